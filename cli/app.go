@@ -644,6 +644,8 @@ func (c *Command) Rest() *RestArgs { return c.register.Rest() }
 
 func (c *Command) Flags() []Flag { return c.register.Flags() }
 
+func (c *Command) VisibleFlags() []Flag { return visibleFlags(c.register.Flags()) }
+
 func (c *Command) Err() error { return c.register.Err() }
 
 func (c *Command) Stdout() io.Writer { return c.app.stdout() }
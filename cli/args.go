@@ -1,10 +1,12 @@
 package cli
 
 type Arg struct {
-	Value     Value
-	Name      string
-	Usage     Usager
-	Necessary Necessary
+	Value       Value
+	Name        string
+	Usage       Usager
+	Necessary   Necessary
+	Hidden      bool   // Hide the arg from the help.
+	Placeholder string // Name of the value in the help. Type of the value if empty.
 
 	set          bool
 	defaultSaved bool
@@ -14,10 +16,12 @@ type Arg struct {
 
 func newArg(value Value, opts ArgOptions) Arg {
 	return Arg{
-		Value:     value,
-		Name:      opts.Name,
-		Usage:     opts.Usage,
-		Necessary: opts.Necessary,
+		Value:       value,
+		Name:        opts.Name,
+		Usage:       opts.Usage,
+		Necessary:   opts.Necessary,
+		Hidden:      opts.Hidden,
+		Placeholder: opts.Placeholder,
 	}
 }
 
@@ -51,7 +55,7 @@ func (a *Arg) Default() (v string, empty bool) {
 }
 
 func (a *Arg) SaveDefault() {
-	a.defaultValue = a.Value.String()
+	a.defaultValue = defaultString(a.Value)
 	if ev, ok := a.Value.(Emptier); ok {
 		a.defaultEmpty = ev.Empty()
 	} else {
@@ -70,6 +74,13 @@ func ArgVar(register Register, value Value, name string, options ...ArgOptionApp
 	opts.applyName(name)
 	opts.applyArgOptions(options)
 
+	// Let the register report the invalid value.
+	if isNilValue(value) {
+		return register.RegisterArg(newArg(value, opts))
+	}
+
+	value = newValidatedValue(value, opts.Name, true, nil, nil, opts.validators)
+
 	return register.RegisterArg(newArg(value, opts))
 }
 
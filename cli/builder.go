@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"io"
+)
+
+// ParseBuilder composes a DefaultParser from multiple configuration sources.
+//
+// Values are applied in priority order: arguments, environment variables,
+// JSON config and defaults.
+//
+// ParseBuilder is immutable, every With* method returns a new builder.
+//
+//   parser := cli.NewParseBuilder().
+//       WithCommands(commander).
+//       WithEnv("MYAPP").
+//       WithJSON(configReader).
+//       Build()
+type ParseBuilder struct {
+	parser    DefaultParser
+	options   []ParserOptionApplyer
+	commander Commander
+	env       bool
+	envPrefix string
+	config    *jsonSource
+	configErr error
+}
+
+// NewParseBuilder returns an empty builder, its Build returns a parser with
+// default modes.
+func NewParseBuilder() ParseBuilder {
+	return ParseBuilder{}
+}
+
+// WithFlags uses modes of the p (Universal, IgnoreUnknownFlags, etc.) as
+// the base of the built parser. The p is cloned (see DefaultParser.Clone).
+func (b ParseBuilder) WithFlags(p *DefaultParser) ParseBuilder {
+	if p != nil {
+		b.parser = *p.Clone()
+	}
+
+	return b
+}
+
+// WithOptions applies parser options to the built parser.
+func (b ParseBuilder) WithOptions(options ...ParserOptionApplyer) ParseBuilder {
+	// Do not share options with the previous builder.
+	b.options = append(b.options[:len(b.options):len(b.options)], options...)
+	return b
+}
+
+// WithCommands sets the commander which is used when Parse is called without
+// a commander.
+func (b ParseBuilder) WithCommands(commander Commander) ParseBuilder {
+	b.commander = commander
+	return b
+}
+
+// WithEnv reads unset flags from environment variables with the prefix
+// (e.g. "log-level" flag with "MYAPP" prefix is read from the MYAPP_LOG_LEVEL).
+func (b ParseBuilder) WithEnv(prefix string) ParseBuilder {
+	b.env = true
+	b.envPrefix = prefix
+	return b
+}
+
+// WithJSON reads unset flags from a JSON object with the flag names as keys.
+//
+// The r is read once, so the builder may build several parsers. A decoding
+// error is returned from the Parse of the built parsers.
+func (b ParseBuilder) WithJSON(r io.Reader) ParseBuilder {
+	source, err := newJSONSource(r)
+	if err != nil {
+		b.config = nil
+		b.configErr = err
+		return b
+	}
+
+	b.config = &source
+	b.configErr = nil
+	return b
+}
+
+// Build returns a new parser composed from the builder. The builder may be
+// used again after the Build.
+func (b ParseBuilder) Build() *DefaultParser {
+	// Do not share sources, middlewares, etc. with the base parser.
+	parser := b.parser.Clone()
+	applyParserOptions(parser, b.options)
+
+	if b.commander != nil {
+		parser.Commander = b.commander
+	}
+
+	if b.env {
+		parser.sources = append(parser.sources, envSource{prefix: b.envPrefix})
+	}
+
+	if b.configErr != nil {
+		// The error will be returned from the Parse.
+		if parser.sourcesErr == nil {
+			parser.sourcesErr = b.configErr
+		}
+	}
+
+	if b.config != nil {
+		parser.sources = append(parser.sources, *b.config)
+	}
+
+	return parser
+}
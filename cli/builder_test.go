@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setenv sets the environment variable and returns a function to restore
+// the previous value.
+func setenv(t *testing.T, key, value string) (restore func()) {
+	t.Helper()
+
+	prev, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatalf("Setenv(%q): %s", key, err)
+	}
+
+	return func() {
+		if ok {
+			_ = os.Setenv(key, prev)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	}
+}
+
+func TestParseBuilder_priority(t *testing.T) {
+	defer setenv(t, "NICETEST_LOG_LEVEL", "warn")()
+	defer setenv(t, "NICETEST_PORT", "8080")()
+
+	config := strings.NewReader(`{
+		"log-level": "debug",
+		"port": 9090,
+		"host": "example.com",
+		"tags": ["a", "b"],
+		"verbose": true
+	}`)
+
+	parser := NewParseBuilder().
+		WithEnv("NICETEST").
+		WithJSON(config).
+		Build()
+
+	var register DefaultRegister
+
+	logLevel := String(&register, "log-level")
+	port := Int(&register, "port")
+	host := String(&register, "host")
+	tags := Strings(&register, "tags")
+	verbose := Bool(&register, "verbose")
+	timeout := Int(&register, "timeout")
+	name := String(&register, "name")
+
+	args := []string{"--port", "1337", "--name", "test"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	const (
+		wantLogLevel = "warn"        // env > json.
+		wantPort     = 1337          // args > env.
+		wantHost     = "example.com" // json > default.
+		wantVerbose  = true
+		wantTimeout  = 0
+		wantName     = "test"
+	)
+	wantTags := []string{"a", "b"}
+
+	if *logLevel != wantLogLevel {
+		t.Errorf("Parse(): log-level: got = %q, want = %q", *logLevel, wantLogLevel)
+	}
+
+	if *port != wantPort {
+		t.Errorf("Parse(): port: got = %v, want = %v", *port, wantPort)
+	}
+
+	if *host != wantHost {
+		t.Errorf("Parse(): host: got = %q, want = %q", *host, wantHost)
+	}
+
+	if !reflect.DeepEqual(*tags, wantTags) {
+		t.Errorf("Parse(): tags: got = %#v, want = %#v", *tags, wantTags)
+	}
+
+	assertParseBoolFlags(t, "verbose", *verbose, wantVerbose)
+
+	if *timeout != wantTimeout {
+		t.Errorf("Parse(): timeout: got = %v, want = %v", *timeout, wantTimeout)
+	}
+
+	if *name != wantName {
+		t.Errorf("Parse(): name: got = %q, want = %q", *name, wantName)
+	}
+}
+
+func TestParseBuilder_immutable(t *testing.T) {
+	base := NewParseBuilder().WithFlags(&DefaultParser{Universal: true})
+	withEnv := base.WithEnv("NICETEST")
+
+	if got := base.Build(); len(got.sources) != 0 {
+		t.Errorf("Build(): base: got %d sources, want 0", len(got.sources))
+	}
+
+	got := withEnv.Build()
+	if len(got.sources) != 1 {
+		t.Errorf("Build(): with env: got %d sources, want 1", len(got.sources))
+	}
+
+	if !got.Universal {
+		t.Errorf("Build(): with env: expected Universal mode from the base parser")
+	}
+}
+
+func TestParseBuilder_shared_base(t *testing.T) {
+	var base DefaultParser
+	for _, names := range [][]string{{"a", "b"}, {"c", "d"}, {"e", "f"}} {
+		if err := MutuallyExclusive(&base, names...); err != nil {
+			t.Fatalf("MutuallyExclusive(%v): failed to add the constraint: %s", names, err)
+		}
+	}
+
+	first := NewParseBuilder().WithFlags(&base).Build()
+	second := NewParseBuilder().WithFlags(&base).Build()
+
+	if err := MutuallyExclusive(first, "x", "y"); err != nil {
+		t.Fatalf("MutuallyExclusive(): failed to add the constraint: %s", err)
+	}
+
+	if err := RequiresTogether(second, "z", "w"); err != nil {
+		t.Fatalf("RequiresTogether(): failed to add the constraint: %s", err)
+	}
+
+	if got, want := first.constraints[3].names, []string{"x", "y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Build(): first: got constraint = %v, want = %v", got, want)
+	}
+
+	if got, want := second.constraints[3].names, []string{"z", "w"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Build(): second: got constraint = %v, want = %v", got, want)
+	}
+
+	if got := len(base.constraints); got != 3 {
+		t.Errorf("Build(): base: got %d constraints, want 3", got)
+	}
+}
+
+func TestParseBuilder_commands(t *testing.T) {
+	var (
+		register DefaultRegister
+		show     *bool
+	)
+
+	commander := &testCommander{
+		commands: []string{"first"},
+		use: func() (Register, error) {
+			var register DefaultRegister
+			show = Bool(&register, "show")
+			return &register, nil
+		},
+	}
+
+	parser := NewParseBuilder().WithCommands(commander).Build()
+
+	if err := parser.Parse(nil, &register, []string{"first", "--show"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	wantPath := []string{"first"}
+	if !reflect.DeepEqual(commander.Path(), wantPath) {
+		t.Errorf("Parse(): path: got = %v, want = %v", commander.Path(), wantPath)
+	}
+
+	assertParseBoolFlags(t, "show", *show, true)
+}
+
+func TestParseBuilder_build_twice(t *testing.T) {
+	builder := NewParseBuilder().WithJSON(strings.NewReader(`{"port": 8080}`))
+
+	for i := 0; i < 2; i++ {
+		var register DefaultRegister
+
+		port := Int(&register, "port")
+
+		if err := builder.Build().Parse(nil, &register, nil); err != nil {
+			t.Fatalf("Parse(): %d: failed to parse args: %s", i, err)
+		}
+
+		if *port != 8080 {
+			t.Errorf("Parse(): %d: port: got = %d, want = %d", i, *port, 8080)
+		}
+	}
+}
+
+func TestParseBuilder_json_empty_key(t *testing.T) {
+	var register DefaultRegister
+
+	parser := NewParseBuilder().WithJSON(strings.NewReader(`{"": 5}`)).Build()
+
+	n := Int(&register, "n")
+
+	if err := parser.Parse(nil, &register, nil); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	// Short-only flags must not be set by the empty key.
+	if *n != 0 {
+		t.Errorf("Parse(): n: got = %d, want = %d", *n, 0)
+	}
+}
+
+func TestParseBuilder_broken_source(t *testing.T) {
+	t.Run("broken json", func(t *testing.T) {
+		var register DefaultRegister
+
+		parser := NewParseBuilder().WithJSON(strings.NewReader(`{"a":`)).Build()
+
+		_ = Int(&register, "a")
+
+		if err := parser.Parse(nil, &register, nil); err == nil {
+			t.Fatalf("Parse(): expected an error for broken JSON")
+		}
+	})
+
+	t.Run("broken value", func(t *testing.T) {
+		defer setenv(t, "NICETEST_COUNT", "abcd")()
+
+		var register DefaultRegister
+
+		parser := NewParseBuilder().WithEnv("NICETEST").Build()
+
+		_ = Int(&register, "count")
+
+		got := parser.Parse(nil, &register, nil)
+		want := &FlagError{
+			Long: "count",
+			Err: &EnvError{
+				Name: "NICETEST_COUNT",
+				Err: &ParseValueError{
+					Type: "int",
+					Err:  ErrSyntax,
+				},
+			},
+		}
+		if !errors.Is(got, want) {
+			t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+		}
+	})
+}
+
+func TestParseBuilder_strict(t *testing.T) {
+	base := &DefaultParser{
+		IgnoreUnknownFlags: true,
+		IgnoreUnknownArgs:  true,
+	}
+
+	parser := NewParseBuilder().
+		WithFlags(base).
+		WithOptions(StrictFlags(), StrictArgs()).
+		Build()
+
+	if parser.IgnoreUnknownFlags {
+		t.Errorf("Build(): expected StrictFlags to disable IgnoreUnknownFlags")
+	}
+
+	if parser.IgnoreUnknownArgs {
+		t.Errorf("Build(): expected StrictArgs to disable IgnoreUnknownArgs")
+	}
+
+	t.Run("unknown flag", func(t *testing.T) {
+		var register DefaultRegister
+
+		_ = Bool(&register, "a")
+
+		got := parser.Parse(nil, &register, []string{"-a", "-b"})
+		want := &ParseFlagError{Name: "-b", Err: ErrUnknown}
+		if !errors.Is(got, want) {
+			t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+		}
+	})
+
+	t.Run("unknown arg", func(t *testing.T) {
+		var register DefaultRegister
+
+		_ = StringArg(&register, "a")
+
+		got := parser.Parse(nil, &register, []string{"first", "second"})
+		want := &ParseArgError{Arg: "second", Index: 1, Err: ErrUnknown}
+		if !errors.Is(got, want) {
+			t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// byte size
+
+var (
+	_ Value   = (*byteSizeValue)(nil)
+	_ Getter  = (*byteSizeValue)(nil)
+	_ Emptier = (*byteSizeValue)(nil)
+	_ Typer   = (*byteSizeValue)(nil)
+)
+
+var byteSizeUnits = [...]struct {
+	suffix string
+	size   int64
+}{
+	// From the largest to the smallest for the String.
+	{"PB", 1 << 50},
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+type byteSizeValue int64
+
+func newByteSizeValue(p *int64) *byteSizeValue {
+	return (*byteSizeValue)(p)
+}
+
+func (v *byteSizeValue) Set(s string) error {
+	// Split the number and the suffix.
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+
+	num := s[:i]
+	suffix := strings.TrimPrefix(s[i:], " ")
+
+	mult := int64(1)
+	if suffix != "" {
+		mult = 0
+		for _, u := range byteSizeUnits {
+			if strings.EqualFold(suffix, u.suffix) {
+				mult = u.size
+				break
+			}
+		}
+	}
+
+	if num == "" || mult == 0 {
+		return &ParseValueError{
+			Type:  "bytesize",
+			Err:   ErrSyntax,
+			Input: s,
+		}
+	}
+
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil {
+		return numError("bytesize", s, err)
+	}
+
+	if n > math.MaxInt64/mult {
+		return &ParseValueError{
+			Type:  "bytesize",
+			Err:   ErrRange,
+			Input: s,
+		}
+	}
+
+	*v = byteSizeValue(n * mult)
+	return nil
+}
+
+func (v *byteSizeValue) Get() interface{} { return int64(*v) }
+
+func (v *byteSizeValue) Empty() bool { return *v == 0 }
+
+func (v *byteSizeValue) String() string {
+	n := int64(*v)
+	for _, u := range byteSizeUnits {
+		if n != 0 && n%u.size == 0 {
+			return strconv.FormatInt(n/u.size, 10) + u.suffix
+		}
+	}
+
+	return strconv.FormatInt(n, 10) + "B"
+}
+
+func (*byteSizeValue) Type() string { return "bytesize" }
+
+// ByteSizeVar defines an int64 flag with specified name which accepts
+// human-readable sizes: "512MB", "2 GB", "1024". Suffixes B, KB, MB, GB, TB
+// and PB are case-insensitive binary multiples (1KB is 1024 bytes), a value
+// without a suffix is a number of bytes.
+// The argument p points to an int64 variable in which to store the value of the flag.
+//
+// Options are the same as for the cli.Int64Var.
+func ByteSizeVar(register Register, p *int64, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newByteSizeValue(p), name, options...)
+}
+
+// ByteSize defines an int64 flag with specified name which accepts
+// human-readable sizes (see cli.ByteSizeVar).
+// The return value is the address of an int64 variable that stores the value of the flag.
+func ByteSize(register Register, name string, options ...FlagOptionApplyer) *int64 {
+	p := new(int64)
+	_ = ByteSizeVar(register, p, name, options...)
+	return p
+}
+
+// ByteSizeArgVar defines an int64 argument with specified name which accepts
+// human-readable sizes (see cli.ByteSizeVar).
+// The argument p points to an int64 variable in which to store the value of the argument.
+func ByteSizeArgVar(register Register, p *int64, name string, options ...ArgOptionApplyer) error {
+	return ArgVar(register, newByteSizeValue(p), name, options...)
+}
+
+// ByteSizeArg defines an int64 argument with specified name which accepts
+// human-readable sizes (see cli.ByteSizeVar).
+// The return value is the address of an int64 variable that stores the value of the argument.
+func ByteSizeArg(register Register, name string, options ...ArgOptionApplyer) *int64 {
+	p := new(int64)
+	_ = ByteSizeArgVar(register, p, name, options...)
+	return p
+}
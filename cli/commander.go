@@ -0,0 +1,161 @@
+package cli
+
+var _ Commander = (*StaticCommander)(nil)
+
+// StaticCommander is a Commander of a flat list of commands. Each command is
+// a function that registers flags and args of the command in a new register.
+//
+//   cmder := cli.NewStaticCommander(map[string]func(cli.Register) error{
+//       "build": func(register cli.Register) error {
+//           _ = cli.Bool(register, "release")
+//           return nil
+//       },
+//   })
+//
+//   err := parser.Parse(cmder, &register, os.Args[1:])
+//
+// NOTE: flags and args are owned by the Register, so the functions receive a
+// register instead of a parser.
+type StaticCommander struct {
+	commands map[string]func(Register) error
+
+	path []string
+}
+
+// NewStaticCommander returns a new StaticCommander for the commands.
+func NewStaticCommander(commands map[string]func(Register) error) *StaticCommander {
+	return &StaticCommander{
+		commands: commands,
+	}
+}
+
+// IsCommand reports whether the name is a command. Only one command may be
+// set.
+func (c *StaticCommander) IsCommand(name string) bool {
+	if len(c.path) > 0 {
+		return false
+	}
+
+	_, ok := c.commands[name]
+	return ok
+}
+
+func (c *StaticCommander) SetCommand(name string) (Register, error) {
+	if name == "" {
+		return nil, &InvalidCommandError{Err: ErrMissingName}
+	}
+
+	fn, ok := c.commands[name]
+	if !ok || len(c.path) > 0 {
+		return nil, &InvalidCommandError{
+			Name: name,
+			Err:  ErrUnknown,
+		}
+	}
+
+	register := &DefaultRegister{}
+	if fn != nil {
+		if err := fn(register); err != nil {
+			return nil, err
+		}
+	}
+
+	c.path = append(c.path, name)
+
+	return register, nil
+}
+
+// Path returns names of set commands in order.
+func (c *StaticCommander) Path() []string { return c.path }
+
+// Reset unsets the command.
+func (c *StaticCommander) Reset() { c.path = c.path[:0] }
+
+var _ Commander = (*TreeCommander)(nil)
+
+// TreeNode is a command of the TreeCommander.
+type TreeNode struct {
+	// Setup registers flags and args of the command in a new register. It may
+	// be nil.
+	Setup func(Register) error
+
+	// Children are subcommands of the command by names.
+	Children map[string]*TreeNode
+}
+
+// TreeCommander is a Commander of nested commands (e.g. "git remote add").
+//
+//   cmder := cli.NewTreeCommander(&cli.TreeNode{
+//       Children: map[string]*cli.TreeNode{
+//           "remote": {
+//               Children: map[string]*cli.TreeNode{
+//                   "add": {Setup: setupRemoteAdd},
+//               },
+//           },
+//       },
+//   })
+type TreeCommander struct {
+	root *TreeNode
+
+	current *TreeNode
+	path    []string
+}
+
+// NewTreeCommander returns a new TreeCommander with the root node. Children
+// of the root are top level commands.
+func NewTreeCommander(root *TreeNode) *TreeCommander {
+	return &TreeCommander{
+		root:    root,
+		current: root,
+	}
+}
+
+// IsCommand reports whether the name is a child of the current command.
+func (c *TreeCommander) IsCommand(name string) bool {
+	_, ok := c.child(name)
+	return ok
+}
+
+func (c *TreeCommander) SetCommand(name string) (Register, error) {
+	if name == "" {
+		return nil, &InvalidCommandError{Err: ErrMissingName}
+	}
+
+	node, ok := c.child(name)
+	if !ok {
+		return nil, &InvalidCommandError{
+			Name: name,
+			Err:  ErrUnknown,
+		}
+	}
+
+	register := &DefaultRegister{}
+	if node.Setup != nil {
+		if err := node.Setup(register); err != nil {
+			return nil, err
+		}
+	}
+
+	c.current = node
+	c.path = append(c.path, name)
+
+	return register, nil
+}
+
+// Path returns names of commands from the root to the current one.
+func (c *TreeCommander) Path() []string { return c.path }
+
+// Reset makes the root the current command.
+func (c *TreeCommander) Reset() {
+	c.current = c.root
+	c.path = c.path[:0]
+}
+
+func (c *TreeCommander) child(name string) (*TreeNode, bool) {
+	if c.current == nil {
+		return nil, false
+	}
+
+	node, ok := c.current.Children[name]
+	return node, ok && node != nil
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStaticCommander(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	verbose := Bool(&register, "verbose")
+
+	var (
+		release *bool
+		target  *string
+		cleaned bool
+	)
+
+	commander := NewStaticCommander(map[string]func(Register) error{
+		"build": func(register Register) error {
+			release = Bool(register, "release")
+			target = StringArg(register, "target")
+			return nil
+		},
+		"clean": func(register Register) error {
+			cleaned = true
+			return nil
+		},
+	})
+
+	args := []string{"--verbose", "build", "--release", "clean"}
+	if err := parser.Parse(commander, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if !*verbose {
+		t.Errorf("Parse(%v): verbose: got = %v, want = %v", args, *verbose, true)
+	}
+
+	if !*release {
+		t.Errorf("Parse(%v): release: got = %v, want = %v", args, *release, true)
+	}
+
+	// Only one command may be set.
+	if *target != "clean" {
+		t.Errorf("Parse(%v): target: got = %q, want = %q", args, *target, "clean")
+	}
+
+	if cleaned {
+		t.Errorf("Parse(%v): clean command must not be set", args)
+	}
+
+	if got, want := commander.Path(), []string{"build"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Path(): got = %v, want = %v", got, want)
+	}
+}
+
+func TestStaticCommander_SetCommand(t *testing.T) {
+	errSetup := errors.New("setup error")
+
+	commander := NewStaticCommander(map[string]func(Register) error{
+		"nil": nil,
+		"broken": func(register Register) error {
+			return errSetup
+		},
+	})
+
+	if _, err := commander.SetCommand(""); !errors.Is(err, &InvalidCommandError{Err: ErrMissingName}) {
+		t.Errorf("SetCommand(%q): got error = %v, want missing name error", "", err)
+	}
+
+	if _, err := commander.SetCommand("unknown"); !errors.Is(err, &InvalidCommandError{Name: "unknown", Err: ErrUnknown}) {
+		t.Errorf("SetCommand(%q): got error = %v, want unknown error", "unknown", err)
+	}
+
+	if _, err := commander.SetCommand("broken"); !errors.Is(err, errSetup) {
+		t.Errorf("SetCommand(%q): got error = %v, want = %v", "broken", err, errSetup)
+	}
+
+	if len(commander.Path()) != 0 {
+		t.Errorf("Path(): got = %v, want empty path", commander.Path())
+	}
+
+	register, err := commander.SetCommand("nil")
+	if err != nil {
+		t.Fatalf("SetCommand(%q): failed to set command: %s", "nil", err)
+	}
+
+	if register == nil {
+		t.Errorf("SetCommand(%q): got nil register", "nil")
+	}
+
+	commander.Reset()
+
+	if !commander.IsCommand("nil") {
+		t.Errorf("IsCommand(%q): got = false after Reset, want = true", "nil")
+	}
+}
+
+func TestTreeCommander(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	var (
+		url      *string
+		fetch    *bool
+		setupLog []string
+	)
+
+	commander := NewTreeCommander(&TreeNode{
+		Children: map[string]*TreeNode{
+			"remote": {
+				Setup: func(register Register) error {
+					setupLog = append(setupLog, "remote")
+					_ = Bool(register, "verbose", WithShort("v"))
+					return nil
+				},
+				Children: map[string]*TreeNode{
+					"add": {
+						Setup: func(register Register) error {
+							setupLog = append(setupLog, "add")
+							fetch = Bool(register, "fetch", WithShort("f"))
+							_ = StringArg(register, "name")
+							url = StringArg(register, "url")
+							return nil
+						},
+					},
+					"remove": nil,
+				},
+			},
+			"status": {},
+		},
+	})
+
+	if commander.IsCommand("add") {
+		t.Errorf("IsCommand(%q): got = true, want = false", "add")
+	}
+
+	args := []string{"remote", "-v", "add", "-f", "origin", "https://example.com"}
+	if err := parser.Parse(commander, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if got, want := commander.Path(), []string{"remote", "add"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Path(): got = %v, want = %v", got, want)
+	}
+
+	if got, want := parser.ParsedCommands(), []string{"remote", "add"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsedCommands(): got = %v, want = %v", got, want)
+	}
+
+	if got, want := setupLog, []string{"remote", "add"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse(%v): setup: got = %v, want = %v", args, got, want)
+	}
+
+	if !*fetch {
+		t.Errorf("Parse(%v): fetch: got = %v, want = %v", args, *fetch, true)
+	}
+
+	if *url != "https://example.com" {
+		t.Errorf("Parse(%v): url: got = %q, want = %q", args, *url, "https://example.com")
+	}
+
+	// Only children of the current command are commands.
+	if commander.IsCommand("status") || commander.IsCommand("remote") {
+		t.Errorf("IsCommand(): siblings and parents must not be commands")
+	}
+
+	commander.Reset()
+
+	if _, err := commander.SetCommand("remove"); !errors.Is(err, &InvalidCommandError{Name: "remove", Err: ErrUnknown}) {
+		t.Errorf("SetCommand(%q): got error = %v, want unknown error", "remove", err)
+	}
+
+	if _, err := commander.SetCommand("status"); err != nil {
+		t.Fatalf("SetCommand(%q): failed to set command: %s", "status", err)
+	}
+
+	if got, want := commander.Path(), []string{"status"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Path(): got = %v, want = %v", got, want)
+	}
+}
+
+func TestParser_ParsedCommands(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	if got := parser.ParsedCommands(); got != nil {
+		t.Errorf("ParsedCommands(): got = %v, want = nil", got)
+	}
+
+	commander := NewStaticCommander(map[string]func(Register) error{
+		"build": nil,
+	})
+
+	if err := parser.Parse(commander, &register, nil); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if got := parser.ParsedCommands(); got == nil || len(got) != 0 {
+		t.Errorf("ParsedCommands(): got = %#v, want empty path", got)
+	}
+
+	if err := parser.Parse(commander, &register, []string{"build"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if got, want := parser.ParsedCommands(), []string{"build"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsedCommands(): got = %v, want = %v", got, want)
+	}
+
+	if err := parser.Parse(nil, &register, nil); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if got := parser.ParsedCommands(); got != nil {
+		t.Errorf("ParsedCommands(): got = %v, want = nil", got)
+	}
+}
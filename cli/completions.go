@@ -105,7 +105,7 @@ func (g *ZSHCompletionGenerator) CompletionGenerate(cmd *Command, w io.Writer) e
 func (g *ZSHCompletionGenerator) generateCommand(cmd *Command, ew *easyWriter) error {
 	app := cmd.App()
 	path := cmd.Path()
-	flags := cmd.Flags()
+	flags := withAllowedForms(cmd.VisibleFlags())
 	args := cmd.Args()
 	rest := cmd.Rest()
 
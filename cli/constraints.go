@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConflictError is returned by the Parse if more than one flag of a
+// MutuallyExclusive group was set.
+type ConflictError struct {
+	Flags []string // Names of set flags.
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("cli: flags %s are mutually exclusive", joinFlagNames(e.Flags))
+}
+
+func (e *ConflictError) Unwrap() error { return ErrConflict }
+
+// Is reports whether err is a ConflictError with the same flags in any order.
+func (e *ConflictError) Is(err error) bool {
+	pe, ok := err.(*ConflictError)
+	return ok && equalSortedStrings(pe.Flags, e.Flags)
+}
+
+// DependencyError is returned by the Parse if only some flags of a
+// RequiresTogether group were set.
+type DependencyError struct {
+	Set     []string // Names of set flags.
+	Missing []string // Names of unset flags.
+}
+
+func (e *DependencyError) Error() string {
+	verb := "requires"
+	if len(e.Set) > 1 {
+		verb = "require"
+	}
+
+	return fmt.Sprintf("cli: %s %s %s", joinFlagNames(e.Set), verb, joinFlagNames(e.Missing))
+}
+
+func (e *DependencyError) Unwrap() error { return ErrDependency }
+
+// Is reports whether err is a DependencyError with the same flags in any
+// order.
+func (e *DependencyError) Is(err error) bool {
+	pe, ok := err.(*DependencyError)
+	return ok && equalSortedStrings(pe.Set, e.Set) && equalSortedStrings(pe.Missing, e.Missing)
+}
+
+// joinFlagNames formats names in the cli.Var form as "--a, -b and --c".
+func joinFlagNames(names []string) string {
+	formatted := make([]string, len(names))
+	for i, name := range names {
+		formatted[i] = formatFlagName(name)
+	}
+
+	if len(formatted) < 2 {
+		return strings.Join(formatted, "")
+	}
+
+	last := len(formatted) - 1
+	return strings.Join(formatted[:last], ", ") + " and " + formatted[last]
+}
+
+// GroupError is returned by the Parse if a constraint of a group of flags
+// is violated, e.g. none of AtLeastOne flags were set.
+type GroupError struct {
+	Group []string
+	Err   error
+}
+
+func (e *GroupError) Error() string {
+	msg := "unknown error"
+	if e.Err != nil {
+		msg = e.Err.Error()
+	}
+
+	return fmt.Sprintf("cli: group error: '%s': %s", strings.Join(e.Group, "', '"), msg)
+}
+
+func (e *GroupError) Unwrap() error { return e.Err }
+
+func (e *GroupError) Is(err error) bool {
+	pe, ok := err.(*GroupError)
+	return ok && equalStrings(pe.Group, e.Group) && errors.Is(pe.Err, e.Err)
+}
+
+type constraintKind int
+
+const (
+	constraintMutuallyExclusive constraintKind = iota
+	constraintRequiresTogether
+	constraintAtLeastOne
+)
+
+type constraint struct {
+	kind  constraintKind
+	names []string
+}
+
+// MutuallyExclusive adds a constraint that at most one of the flags may be
+// set. Flags are checked by the Parse after all sources were applied, so the
+// flags may be registered later.
+//
+//   _ = cli.Bool(register, "json")
+//   _ = cli.Bool(register, "yaml")
+//
+//   _ = cli.MutuallyExclusive(&parser, "json", "yaml")
+func MutuallyExclusive(p *DefaultParser, names ...string) error {
+	return p.addConstraint(constraintMutuallyExclusive, names)
+}
+
+// RequiresTogether adds a constraint that either all or none of the flags
+// are set.
+//
+//   _ = cli.RequiresTogether(&parser, "output-file", "output-format")
+func RequiresTogether(p *DefaultParser, names ...string) error {
+	return p.addConstraint(constraintRequiresTogether, names)
+}
+
+// AtLeastOne adds a constraint that at least one of the flags is set.
+//
+//   _ = cli.AtLeastOne(&parser, "file", "url")
+func AtLeastOne(p *DefaultParser, names ...string) error {
+	return p.addConstraint(constraintAtLeastOne, names)
+}
+
+func (p *DefaultParser) addConstraint(kind constraintKind, names []string) error {
+	if len(names) < 2 {
+		return ErrNotEnough
+	}
+
+	for _, name := range names {
+		if name == "" {
+			return &FlagError{Err: ErrMissingName}
+		}
+	}
+
+	p.constraints = append(p.constraints, constraint{
+		kind:  kind,
+		names: append([]string(nil), names...),
+	})
+
+	return nil
+}
+
+// checkConstraints checks constraints in the order they were added. Names
+// are resolved in the registers of the parse from the last command to the
+// root, so constraints of root flags work with commands. It returns Errors
+// in the AllErrors mode.
+func (p *DefaultParser) checkConstraints(registers []Register) error {
+	var errs []error
+	for _, c := range p.constraints {
+		var set, unset []string
+		for _, name := range c.names {
+			flag, ok := lookupFlagIn(registers, name)
+			if !ok {
+				return &FlagError{
+					Short: shortName(name),
+					Long:  longName(name),
+					Err:   ErrUnknown,
+				}
+			}
+
+			if flag.Set() {
+				set = append(set, name)
+			} else {
+				unset = append(unset, name)
+			}
+		}
+
+		var err error
+		switch c.kind {
+		case constraintMutuallyExclusive:
+			if len(set) > 1 {
+				err = &ConflictError{Flags: set}
+			}
+
+		case constraintRequiresTogether:
+			if len(set) > 0 && len(unset) > 0 {
+				err = &DependencyError{Set: set, Missing: unset}
+			}
+
+		case constraintAtLeastOne:
+			if len(set) == 0 {
+				err = &GroupError{Group: c.names, Err: ErrNotProvided}
+			}
+		}
+
+		if err != nil {
+			if !p.AllErrors {
+				return err
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	return joinErrors(errs)
+}
+
+// lookupFlag finds the flag by a name in the cli.Var form: long if it's
+// longer than one char, short otherwise.
+func lookupFlag(r Register, name string) (*Flag, bool) {
+	if len(name) > 1 {
+		return r.LongFlag(name)
+	}
+
+	return r.ShortFlag(name)
+}
+
+// lookupFlagIn finds the flag in the registers starting from the last one.
+func lookupFlagIn(registers []Register, name string) (*Flag, bool) {
+	for i := len(registers) - 1; i >= 0; i-- {
+		if flag, ok := lookupFlag(registers[i], name); ok {
+			return flag, true
+		}
+	}
+
+	return nil, false
+}
+
+// formatFlagName formats the name in the cli.Var form as "--long" or "-s".
+func formatFlagName(name string) string {
+	if len(name) > 1 {
+		return "--" + name
+	}
+
+	return "-" + name
+}
+
+func shortName(name string) string {
+	if len(name) == 1 {
+		return name
+	}
+
+	return ""
+}
+
+func longName(name string) string {
+	if len(name) > 1 {
+		return name
+	}
+
+	return ""
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalSortedStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+
+	return equalStrings(a, b)
+}
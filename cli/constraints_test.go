@@ -0,0 +1,323 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMutuallyExclusive(t *testing.T) {
+	tt := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{
+			name: "none",
+			args: nil,
+		},
+		{
+			name: "one",
+			args: []string{"--json"},
+		},
+		{
+			name:    "both",
+			args:    []string{"--json", "--yaml"},
+			wantErr: &ConflictError{Flags: []string{"json", "yaml"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			if err := MutuallyExclusive(&parser, "json", "yaml"); err != nil {
+				t.Fatalf("MutuallyExclusive(): failed to add the constraint: %s", err)
+			}
+
+			_ = Bool(&register, "json")
+			_ = Bool(&register, "yaml")
+
+			err := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMutuallyExclusive_invalid(t *testing.T) {
+	var parser DefaultParser
+
+	if err := MutuallyExclusive(&parser, "json"); !errors.Is(err, ErrNotEnough) {
+		t.Errorf("MutuallyExclusive(): got error = %v, want error = %v", err, ErrNotEnough)
+	}
+
+	want := &FlagError{Err: ErrMissingName}
+	if err := MutuallyExclusive(&parser, "json", ""); !errors.Is(err, want) {
+		t.Errorf("MutuallyExclusive(): got error = %v, want error = %v", err, want)
+	}
+}
+
+func TestMutuallyExclusive_unknown_flag(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Bool(&register, "json")
+
+	if err := MutuallyExclusive(&parser, "json", "x"); err != nil {
+		t.Fatalf("MutuallyExclusive(): failed to add the constraint: %s", err)
+	}
+
+	got := parser.Parse(nil, &register, nil)
+	want := &FlagError{Short: "x", Err: ErrUnknown}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(): got error = %v, want error = %v", got, want)
+	}
+}
+
+func TestConstraints_commands(t *testing.T) {
+	tt := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{
+			name: "root flag",
+			args: []string{"--json", "run", "--fast"},
+		},
+		{
+			name:    "root conflict",
+			args:    []string{"--json", "--yaml", "run"},
+			wantErr: &ConflictError{Flags: []string{"json", "yaml"}},
+		},
+		{
+			name: "command flag unset",
+			args: []string{"--yaml", "run"},
+		},
+		{
+			name:    "command missing dependency",
+			args:    []string{"run", "--fast"},
+			wantErr: &DependencyError{Set: []string{"fast"}, Missing: []string{"json"}},
+		},
+		{
+			name:    "no output",
+			args:    []string{"--output", "out.txt", "run"},
+			wantErr: &GroupError{Group: []string{"json", "yaml"}, Err: ErrNotProvided},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			_ = Bool(&register, "json")
+			_ = Bool(&register, "yaml")
+			_ = String(&register, "output")
+
+			commander := NewStaticCommander(map[string]func(Register) error{
+				"run": func(register Register) error {
+					_ = Bool(register, "fast")
+					return nil
+				},
+			})
+
+			if err := MutuallyExclusive(&parser, "json", "yaml"); err != nil {
+				t.Fatalf("MutuallyExclusive(): failed to add the constraint: %s", err)
+			}
+
+			if err := RequiresTogether(&parser, "fast", "json"); err != nil {
+				t.Fatalf("RequiresTogether(): failed to add the constraint: %s", err)
+			}
+
+			if err := AtLeastOne(&parser, "json", "yaml"); err != nil {
+				t.Fatalf("AtLeastOne(): failed to add the constraint: %s", err)
+			}
+
+			err := parser.Parse(commander, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequiresTogether(t *testing.T) {
+	tt := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{
+			name: "none",
+			args: nil,
+		},
+		{
+			name: "all",
+			args: []string{"--output-file", "out.json", "--output-format", "json", "-z"},
+		},
+		{
+			name: "some",
+			args: []string{"--output-file", "out.json"},
+			wantErr: &DependencyError{
+				Set:     []string{"output-file"},
+				Missing: []string{"output-format", "z"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			if err := RequiresTogether(&parser, "output-file", "output-format", "z"); err != nil {
+				t.Fatalf("RequiresTogether(): failed to add the constraint: %s", err)
+			}
+
+			_ = String(&register, "output-file")
+			_ = String(&register, "output-format")
+			_ = Bool(&register, "z")
+
+			err := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAtLeastOne(t *testing.T) {
+	tt := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{
+			name:    "none",
+			args:    nil,
+			wantErr: &GroupError{Group: []string{"file", "url"}, Err: ErrNotProvided},
+		},
+		{
+			name: "one",
+			args: []string{"--url", "http://localhost"},
+		},
+		{
+			name: "both",
+			args: []string{"--file", "index.html", "--url", "http://localhost"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			if err := AtLeastOne(&parser, "file", "url"); err != nil {
+				t.Fatalf("AtLeastOne(): failed to add the constraint: %s", err)
+			}
+
+			_ = String(&register, "file")
+			_ = String(&register, "url")
+
+			err := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+
+			if tc.wantErr != nil && !errors.Is(err, ErrNotProvided) {
+				t.Errorf("Is(%v): expected errors will be matched", ErrNotProvided)
+			}
+		})
+	}
+}
+
+func TestConstraints_order(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   = DefaultParser{AllErrors: true}
+	)
+
+	_ = AtLeastOne(&parser, "file", "url")
+	_ = MutuallyExclusive(&parser, "json", "yaml")
+
+	_ = String(&register, "file")
+	_ = String(&register, "url")
+	_ = Bool(&register, "json")
+	_ = Bool(&register, "yaml")
+
+	args := []string{"--json", "--yaml"}
+
+	err := parser.Parse(nil, &register, args)
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Parse(%v): got error = %v, want 2 errors", args, err)
+	}
+
+	want := []error{
+		&GroupError{Group: []string{"file", "url"}, Err: ErrNotProvided},
+		&ConflictError{Flags: []string{"json", "yaml"}},
+	}
+	for i := range want {
+		if !errors.Is(errs[i], want[i]) {
+			t.Errorf("Parse(%v): error #%d: got = %v, want = %v", args, i, errs[i], want[i])
+		}
+	}
+}
+
+func TestConflictError(t *testing.T) {
+	err := &ConflictError{Flags: []string{"json", "yaml"}}
+
+	const wantMsg = "cli: flags --json and --yaml are mutually exclusive"
+	if err.Error() != wantMsg {
+		t.Errorf("Error(): got = %q, want = %q", err.Error(), wantMsg)
+	}
+
+	if !errors.Is(err, &ConflictError{Flags: []string{"yaml", "json"}}) {
+		t.Errorf("Is(): expected errors with flags in another order will be matched")
+	}
+
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Is(%v): expected errors will be matched", ErrConflict)
+	}
+
+	if errors.Is(err, ErrDependency) {
+		t.Errorf("Is(%v): unexpected errors will be matched", ErrDependency)
+	}
+}
+
+func TestDependencyError(t *testing.T) {
+	err := &DependencyError{
+		Set:     []string{"output-file"},
+		Missing: []string{"output-format", "z"},
+	}
+
+	const wantMsg = "cli: --output-file requires --output-format and -z"
+	if err.Error() != wantMsg {
+		t.Errorf("Error(): got = %q, want = %q", err.Error(), wantMsg)
+	}
+
+	if !errors.Is(err, &DependencyError{Set: []string{"output-file"}, Missing: []string{"z", "output-format"}}) {
+		t.Errorf("Is(): expected errors with flags in another order will be matched")
+	}
+
+	if !errors.Is(err, ErrDependency) {
+		t.Errorf("Is(%v): expected errors will be matched", ErrDependency)
+	}
+
+	if errors.Is(err, ErrConflict) {
+		t.Errorf("Is(%v): unexpected errors will be matched", ErrConflict)
+	}
+}
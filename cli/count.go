@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"strconv"
+)
+
+// count
+
+var (
+	_ Value    = (*countValue)(nil)
+	_ Getter   = (*countValue)(nil)
+	_ Emptier  = (*countValue)(nil)
+	_ Typer    = (*countValue)(nil)
+	_ boolFlag = (*countValue)(nil)
+)
+
+type countValue int
+
+func newCountValue(p *int) *countValue {
+	return (*countValue)(p)
+}
+
+func (v *countValue) Set(s string) error {
+	if s == "" {
+		*v++
+		return nil
+	}
+
+	// Numbers set the count directly (e.g. --verbose=5).
+	if n, err := strconv.ParseInt(s, 0, strconv.IntSize); err == nil {
+		if n < 0 {
+			return &ParseValueError{
+				Type:  "count",
+				Err:   ErrRange,
+				Input: s,
+			}
+		}
+
+		*v = countValue(n)
+		return nil
+	}
+
+	b, err := parseBool(s)
+	if err != nil {
+		return &ParseValueError{
+			Type:  "count",
+			Err:   err,
+			Input: s,
+		}
+	}
+
+	if b {
+		*v++
+	} else {
+		*v = 0
+	}
+
+	return nil
+}
+
+func (v *countValue) Get() interface{} { return int(*v) }
+
+func (v *countValue) Empty() bool { return *v == 0 }
+
+func (v *countValue) String() string { return strconv.Itoa(int(*v)) }
+
+func (*countValue) Type() string { return "count" }
+
+func (*countValue) IsBoolFlag() bool { return true }
+
+// CountVar defines an int flag with specified name which counts occurrences
+// of the flag: "-v -v -v" and "-vvv" are 3. The flag doesn't need a value
+// like a bool flag, "false" resets the count and a number sets it directly.
+// The argument p points to an int variable in which to store the value of the flag.
+//
+// Options are the same as for the cli.BoolVar.
+func CountVar(register Register, p *int, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newCountValue(p), name, options...)
+}
+
+// Count defines an int flag with specified name which counts occurrences of
+// the flag (see cli.CountVar).
+// The return value is the address of an int variable that stores the value of the flag.
+func Count(register Register, name string, options ...FlagOptionApplyer) *int {
+	p := new(int)
+	_ = CountVar(register, p, name, options...)
+	return p
+}
@@ -0,0 +1,96 @@
+package cli_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SuperPaintman/nice/cli"
+)
+
+// exampleCommander is a minimal Commander with "server start" commands.
+type exampleCommander struct {
+	path []string
+
+	port   *int
+	detach *bool
+	name   *string
+	rest   *[]string
+}
+
+func (c *exampleCommander) IsCommand(name string) bool {
+	switch len(c.path) {
+	case 0:
+		return name == "server"
+
+	case 1:
+		return name == "start"
+
+	default:
+		return false
+	}
+}
+
+func (c *exampleCommander) SetCommand(name string) (cli.Register, error) {
+	if !c.IsCommand(name) {
+		return nil, fmt.Errorf("unknown command: %s", name)
+	}
+
+	c.path = append(c.path, name)
+
+	register := &cli.DefaultRegister{}
+
+	if name == "start" {
+		c.port = cli.Int(register, "port",
+			cli.WithShort("p"),
+			cli.Usage("Port to listen"),
+		)
+		c.detach = cli.Bool(register, "d", cli.WithLong("detach"))
+		c.name = cli.StringArg(register, "name", cli.Usage("Name of the server"))
+		c.rest = cli.RestStrings(register, "extra")
+	}
+
+	return register, nil
+}
+
+func ExampleDefaultParser_Parse() {
+	var (
+		register  cli.DefaultRegister
+		parser    cli.DefaultParser
+		commander exampleCommander
+	)
+
+	// Global flags.
+	logLevel := cli.String(&register, "log-level",
+		cli.WithShort("l"),
+		cli.Usage("Log level"),
+	)
+	config := cli.String(&register, "config", cli.Usage("Path to the config"))
+
+	args := []string{
+		"-l", "debug", "--config=/etc/app.conf",
+		"server", "start",
+		"-p", "8080", "--detach", "web", "a", "b",
+	}
+
+	if err := parser.Parse(&commander, &register, args); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println("path:", strings.Join(commander.path, " "))
+	fmt.Println("log-level:", *logLevel)
+	fmt.Println("config:", *config)
+	fmt.Println("port:", *commander.port)
+	fmt.Println("detach:", *commander.detach)
+	fmt.Println("name:", *commander.name)
+	fmt.Println("extra:", *commander.rest)
+
+	// Output:
+	// path: server start
+	// log-level: debug
+	// config: /etc/app.conf
+	// port: 8080
+	// detach: true
+	// name: web
+	// extra: [a b]
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"io/ioutil"
+	"strings"
+)
+
+// file string
+
+var (
+	_ Value      = (*fileStringValue)(nil)
+	_ Getter     = (*fileStringValue)(nil)
+	_ Emptier    = (*fileStringValue)(nil)
+	_ Typer      = (*fileStringValue)(nil)
+	_ stringFlag = (*fileStringValue)(nil)
+)
+
+// defaultFilePrefix marks values of FileString flags which are file paths.
+const defaultFilePrefix = "@"
+
+type fileStringValue struct {
+	p      *string
+	prefix string
+}
+
+func newFileStringValue(p *string, prefix string) *fileStringValue {
+	if p == nil {
+		return nil
+	}
+
+	return &fileStringValue{p: p, prefix: prefix}
+}
+
+func (v *fileStringValue) Set(s string) error {
+	if v.prefix == "" || !strings.HasPrefix(s, v.prefix) {
+		*v.p = s
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(s[len(v.prefix):])
+	if err != nil {
+		return &ParseValueError{
+			Type:  "filestring",
+			Err:   ErrSyntax,
+			Input: s,
+		}
+	}
+
+	*v.p = strings.TrimSpace(string(data))
+	return nil
+}
+
+func (v *fileStringValue) Get() interface{} { return *v.p }
+
+func (v *fileStringValue) Empty() bool { return *v.p == "" }
+
+func (v *fileStringValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+
+	return *v.p
+}
+
+func (*fileStringValue) Type() string { return "string" }
+
+func (*fileStringValue) IsStringFlag() bool { return true }
+
+// WithFilePrefix replaces the "@" prefix of file paths in values of the
+// cli.FileString flag. An empty prefix disables reading of files.
+//
+//   _ = cli.FileString(register, "config", cli.WithFilePrefix("file:"))
+func WithFilePrefix(prefix string) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.filePrefix = &prefix
+	}
+}
+
+// FileStringVar defines a string flag with specified name which reads the
+// value from a file if the value starts with "@" (e.g. --token @token.txt).
+// The content of the file is trimmed. The prefix may be changed by the
+// cli.WithFilePrefix.
+// The argument p points to a string variable in which to store the value of the flag.
+//
+// Options are the same as for the cli.StringVar.
+func FileStringVar(register Register, p *string, name string, options ...FlagOptionApplyer) error {
+	var opts FlagOptions
+	opts.applyFlagOptions(options)
+
+	prefix := defaultFilePrefix
+	if opts.filePrefix != nil {
+		prefix = *opts.filePrefix
+	}
+
+	return Var(register, newFileStringValue(p, prefix), name, options...)
+}
+
+// FileString defines a file string flag with specified name (see
+// cli.FileStringVar).
+// The return value is the address of a string variable that stores the value of the flag.
+func FileString(register Register, name string, options ...FlagOptionApplyer) *string {
+	p := new(string)
+	_ = FileStringVar(register, p, name, options...)
+	return p
+}
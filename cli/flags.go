@@ -1,17 +1,29 @@
 package cli
 
 type Flag struct {
-	Value     Value
-	Short     string
-	Long      string
-	Usage     Usager
-	Necessary Necessary
+	Value       Value
+	Short       string
+	Long        string
+	Usage       Usager
+	Necessary   Necessary
+	Env         string            // Environment variable with a value of the flag.
+	Choices     []string          // Allowed values of the flag.
+	Deprecated  string            // Deprecation message.
+	Group       *FlagGroup        // Group of the flag in the help.
+	Hidden      bool              // Hide the flag from the help and completions.
+	Persistent  bool              // Inherit the flag by sub-parsers (see DefaultParser.SubParser).
+	Placeholder string            // Name of the value in the help. Type of the value if empty.
+	Sensitive   bool              // Do not show the default value in the help.
+	ShortOnly   bool              // Accept only the short form, the long name is for lookups.
+	LongOnly    bool              // Accept only the long form, the short name is for lookups.
+	Annotations map[string]string // Metadata of the flag, it doesn't affect the parsing.
 
 	set          bool
 	defaultSaved bool
 	defaultValue string
 	defaultEmpty bool
 	commandFlag  bool
+	err          error // Error of the options (e.g. a broken default) reported by the RegisterFlag.
 
 	// NOTE(SuperPaintman):
 	//     The first version had "Aliases" for flags. It's quite handy to have
@@ -22,13 +34,31 @@ type Flag struct {
 	//     developers can easely make a workaround if they need it.
 }
 
+// FlagGroup bundles related flags in the help. It doesn't affect the
+// parsing.
+type FlagGroup struct {
+	Name        string
+	Description string
+}
+
 func newFlag(value Value, opts FlagOptions) Flag {
 	return Flag{
-		Value:     value,
-		Short:     opts.Short,
-		Long:      opts.Long,
-		Usage:     opts.Usage,
-		Necessary: opts.Necessary,
+		Value:       value,
+		Short:       opts.Short,
+		Long:        opts.Long,
+		Usage:       opts.Usage,
+		Necessary:   opts.Necessary,
+		Env:         opts.Env,
+		Choices:     opts.Choices,
+		Deprecated:  opts.Deprecated,
+		Group:       opts.Group,
+		Hidden:      opts.Hidden,
+		Persistent:  opts.Persistent,
+		Placeholder: opts.Placeholder,
+		Sensitive:   opts.Sensitive,
+		ShortOnly:   opts.ShortOnly,
+		LongOnly:    opts.LongOnly,
+		Annotations: opts.Annotations,
 
 		commandFlag: opts.commandFlag,
 	}
@@ -42,6 +72,54 @@ func (f *Flag) Type() string {
 	return ""
 }
 
+// name returns the long name of the flag or the short if it has no long.
+func (f *Flag) name() string {
+	if f.Long != "" {
+		return f.Long
+	}
+
+	return f.Short
+}
+
+// shortForm returns the short name if the short form of the flag is allowed
+// (see WithLongOnly).
+func (f *Flag) shortForm() string {
+	if f.LongOnly {
+		return ""
+	}
+
+	return f.Short
+}
+
+// longForm returns the long name if the long form of the flag is allowed (see
+// WithShortOnly).
+func (f *Flag) longForm() string {
+	if f.ShortOnly {
+		return ""
+	}
+
+	return f.Long
+}
+
+// withAllowedForms clears names of disallowed forms in the flags, e.g. to
+// show only allowed forms in the help. Flags must be copies of registered
+// ones.
+func withAllowedForms(flags []Flag) []Flag {
+	for i := range flags {
+		flags[i].Short = flags[i].shortForm()
+		flags[i].Long = flags[i].longForm()
+	}
+
+	return flags
+}
+
+// Annotation returns the value of the annotation by the key (see
+// WithAnnotation).
+func (f *Flag) Annotation(key string) (value string, ok bool) {
+	value, ok = f.Annotations[key]
+	return value, ok
+}
+
 func (f *Flag) Required() bool {
 	return f.Necessary == Required
 }
@@ -63,7 +141,7 @@ func (f *Flag) Default() (v string, empty bool) {
 }
 
 func (f *Flag) SaveDefault() {
-	f.defaultValue = f.Value.String()
+	f.defaultValue = defaultString(f.Value)
 	if ev, ok := f.Value.(Emptier); ok {
 		f.defaultEmpty = ev.Empty()
 	} else {
@@ -96,12 +174,119 @@ func (f *Flag) String() string {
 	return v
 }
 
+// errDuplicateLong is returned if the long name is set by the name of the
+// cli.Var and the cli.WithLong at the same time. It's the ErrDuplicate which
+// wraps the ErrInvalidName.
+var errDuplicateLong error = &duplicateNameError{}
+
+type duplicateNameError struct{}
+
+func (*duplicateNameError) Error() string {
+	return ErrDuplicate.Error() + ": " + ErrInvalidName.Error()
+}
+
+func (*duplicateNameError) Unwrap() error { return ErrInvalidName }
+
+func (*duplicateNameError) Is(err error) bool { return err == ErrDuplicate }
+
 func Var(register Register, value Value, name string, options ...FlagOptionApplyer) error {
 	var opts FlagOptions
 	opts.applyName(name)
+
+	// Apply options without the long name to find out if cli.WithLong sets
+	// it too.
+	long := opts.Long
+	opts.Long = ""
 	opts.applyFlagOptions(options)
 
-	return register.RegisterFlag(newFlag(value, opts))
+	// Let the register report errors, helpers like cli.Int discard errors of
+	// the cli.Var.
+	if long != "" && opts.Long != "" {
+		flag := newFlag(value, opts)
+		flag.Long = long
+		flag.err = &FlagError{
+			Short: opts.Short,
+			Long:  long,
+			Err:   errDuplicateLong,
+		}
+
+		return register.RegisterFlag(flag)
+	}
+
+	if opts.Long == "" {
+		opts.Long = long
+	}
+
+	// Let the register report the invalid value.
+	if isNilValue(value) {
+		return register.RegisterFlag(newFlag(value, opts))
+	}
+
+	flag := newFlag(value, opts)
+
+	var check func(string) error
+	if len(opts.Choices) > 0 {
+		check = choicesCheck(flag.Type(), opts.Choices, opts.caseFoldChoices)
+	}
+
+	flag.Value = newValidatedValue(value, flag.name(), false, opts.transforms, check, opts.validators)
+
+	if opts.defaultValue != nil {
+		if err := flag.Value.Set(*opts.defaultValue); err != nil {
+			flag.err = &FlagError{
+				Short: opts.Short,
+				Long:  opts.Long,
+				Err:   err,
+			}
+
+			return register.RegisterFlag(flag)
+		}
+
+		flag.SaveDefault()
+	}
+
+	if err := register.RegisterFlag(flag); err != nil {
+		return err
+	}
+
+	for _, ref := range opts.refs {
+		if ref != nil {
+			*ref = FlagRef{register: register, short: flag.Short, long: flag.Long}
+		}
+	}
+
+	return nil
+}
+
+// FlagRef is a reference to a registered flag, see WithRef.
+type FlagRef struct {
+	register Register
+	short    string
+	long     string
+}
+
+// Flag returns the referenced flag or nil if the reference is empty.
+func (ref FlagRef) Flag() *Flag {
+	if ref.register == nil {
+		return nil
+	}
+
+	// NOTE(SuperPaintman):
+	//     The register may reallocate its flags on next registrations, so we
+	//     look the flag up every time instead of keeping a pointer.
+	if ref.long != "" {
+		flag, _ := ref.register.LongFlag(ref.long)
+		return flag
+	}
+
+	flag, _ := ref.register.ShortFlag(ref.short)
+	return flag
+}
+
+// IsSet reports whether the referenced flag was set by the last parse.
+func (ref FlagRef) IsSet() bool {
+	flag := ref.Flag()
+	return flag != nil && flag.Set()
 }
 
 //go:generate python ./generate_flags.py
@@ -0,0 +1,100 @@
+package cli
+
+import "reflect"
+
+var _ Register = (*FlagSet)(nil)
+
+// FlagSet is a reusable bundle of flags and args, e.g. flags of a database
+// connection shared by several commands. Flags are registered in the FlagSet
+// as in a register and added to registers of a parser by the
+// DefaultParser.AddFlagSet.
+//
+//   var db cli.FlagSet
+//
+//   host := cli.String(&db, "host", cli.WithDefault("localhost"))
+//   port := cli.Int(&db, "port", cli.WithDefault("5432"))
+//
+//   if err := parser.AddFlagSet(&db); err != nil {
+//       return err
+//   }
+type FlagSet struct {
+	DefaultRegister
+}
+
+// AddFlagSet adds flags, args and rest args of the set into every register of
+// the Parse, after flags and args of the register. It returns the first
+// registration error of the set.
+func (p *DefaultParser) AddFlagSet(fs *FlagSet) error {
+	if err := fs.Err(); err != nil {
+		return err
+	}
+
+	p.flagSets = append(p.flagSets, fs)
+
+	return nil
+}
+
+func (p *DefaultParser) registerFlagSets(r Register) error {
+	for _, fs := range p.flagSets {
+		for _, flag := range fs.Flags() {
+			// The register may be parsed again.
+			if found, ok := r.LongFlag(flag.Long); ok && flag.Long != "" && sameValue(found.Value, flag.Value) {
+				continue
+			}
+
+			if found, ok := r.ShortFlag(flag.Short); ok && flag.Short != "" && sameValue(found.Value, flag.Value) {
+				continue
+			}
+
+			flag.set = false
+			if err := r.RegisterFlag(flag); err != nil {
+				return err
+			}
+		}
+
+		for _, arg := range fs.Args() {
+			if hasArg(r, arg) {
+				continue
+			}
+
+			arg.set = false
+			if err := r.RegisterArg(arg); err != nil {
+				return err
+			}
+		}
+
+		if rest := fs.Rest(); rest != nil {
+			if found := r.Rest(); found != nil && sameValue(found.Values, rest.Values) {
+				continue
+			}
+
+			if err := r.RegisterRestArgs(*rest); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasArg(r Register, arg Arg) bool {
+	args := r.Args()
+	for i := range args {
+		if args[i].Name == arg.Name && sameValue(args[i].Value, arg.Value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameValue reports whether both values point to the same variable.
+func sameValue(a, b Value) bool {
+	va := reflect.ValueOf(a)
+	vb := reflect.ValueOf(b)
+	if va.Kind() != reflect.Ptr || vb.Kind() != reflect.Ptr {
+		return false
+	}
+
+	return va.Type() == vb.Type() && va.Pointer() == vb.Pointer()
+}
@@ -0,0 +1,60 @@
+package cli
+
+// func
+
+var (
+	_ Value      = (*funcValue)(nil)
+	_ Getter     = (*funcValue)(nil)
+	_ Typer      = (*funcValue)(nil)
+	_ stringFlag = (*funcValue)(nil)
+)
+
+type funcValue struct {
+	fn   func(string) error
+	last string
+}
+
+func newFuncValue(fn func(string) error) Value {
+	if fn == nil {
+		return nil
+	}
+
+	return &funcValue{fn: fn}
+}
+
+func (v *funcValue) Set(s string) error {
+	if err := v.fn(s); err != nil {
+		return err
+	}
+
+	v.last = s
+	return nil
+}
+
+func (v *funcValue) Get() interface{} { return v.last }
+
+func (v *funcValue) String() string { return v.last }
+
+func (*funcValue) Type() string { return "func" }
+
+func (*funcValue) IsStringFlag() bool { return true }
+
+// FuncFlag defines a flag with specified name which calls the fn for every
+// value of the flag. An error of the fn is returned by the parser as is
+// inside the cli.FlagError.
+//
+//   _ = cli.FuncFlag(register, "include", func(value string) error {
+//       includes = append(includes, value)
+//       return nil
+//   })
+//
+// Options are the same as for the cli.StringVar.
+func FuncFlag(register Register, name string, fn func(string) error, options ...FlagOptionApplyer) error {
+	return Var(register, newFuncValue(fn), name, options...)
+}
+
+// FuncArg defines a positional arg with specified name which calls the fn
+// for the value of the arg (see cli.FuncFlag).
+func FuncArg(register Register, name string, fn func(string) error, options ...ArgOptionApplyer) error {
+	return ArgVar(register, newFuncValue(fn), name, options...)
+}
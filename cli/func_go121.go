@@ -0,0 +1,67 @@
+//go:build go1.21
+// +build go1.21
+
+package cli
+
+import "fmt"
+
+// func[T]
+
+var (
+	_ Value      = (*parseFuncValue[int])(nil)
+	_ Getter     = (*parseFuncValue[int])(nil)
+	_ Typer      = (*parseFuncValue[int])(nil)
+	_ stringFlag = (*parseFuncValue[int])(nil)
+)
+
+type parseFuncValue[T any] struct {
+	p     *T
+	parse func(string) (T, error)
+}
+
+func newParseFuncValue[T any](p *T, parse func(string) (T, error)) Value {
+	if parse == nil {
+		return nil
+	}
+
+	return &parseFuncValue[T]{p: p, parse: parse}
+}
+
+func (v *parseFuncValue[T]) Set(s string) error {
+	val, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+
+	*v.p = val
+	return nil
+}
+
+func (v *parseFuncValue[T]) Get() interface{} { return *v.p }
+
+func (v *parseFuncValue[T]) String() string { return fmt.Sprintf("%v", *v.p) }
+
+func (*parseFuncValue[T]) Type() string { return "func" }
+
+func (*parseFuncValue[T]) IsStringFlag() bool { return true }
+
+// FlagFunc defines a flag with specified name which value is parsed by the
+// parse. An error of the parse is returned by the parser as is inside the
+// cli.FlagError, so the parse should return the cli.ParseValueError.
+// The return value is the address of a T variable that stores the value of
+// the flag.
+//
+//   id := cli.FlagFunc(register, "id", func(s string) (uuid.UUID, error) {
+//       id, err := uuid.Parse(s)
+//       if err != nil {
+//           return uuid.UUID{}, &cli.ParseValueError{Type: "uuid", Err: cli.ErrSyntax, Input: s}
+//       }
+//       return id, nil
+//   })
+//
+// Options are the same as for the cli.StringVar.
+func FlagFunc[T any](register Register, name string, parse func(string) (T, error), options ...FlagOptionApplyer) *T {
+	p := new(T)
+	_ = Var(register, newParseFuncValue(p, parse), name, options...)
+	return p
+}
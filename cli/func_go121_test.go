@@ -0,0 +1,90 @@
+//go:build go1.21
+// +build go1.21
+
+package cli
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testUUID [16]byte
+
+func (u testUUID) String() string {
+	s := hex.EncodeToString(u[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+func parseTestUUID(s string) (testUUID, error) {
+	var u testUUID
+
+	raw := strings.ReplaceAll(s, "-", "")
+	if len(raw) != 32 || len(s) != 36 {
+		return u, &ParseValueError{Type: "uuid", Err: ErrSyntax, Input: s}
+	}
+
+	if _, err := hex.Decode(u[:], []byte(raw)); err != nil {
+		return u, &ParseValueError{Type: "uuid", Err: ErrSyntax, Input: s}
+	}
+
+	return u, nil
+}
+
+func TestFlagFunc(t *testing.T) {
+	const want = "123e4567-e89b-12d3-a456-426614174000"
+
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	id := FlagFunc(&register, "id", parseTestUUID)
+
+	args := []string{"--id", want}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if got := id.String(); got != want {
+		t.Errorf("Parse(%v): got = %q, want = %q", args, got, want)
+	}
+
+	flag, _ := register.LongFlag("id")
+	if got := flag.Value.String(); got != want {
+		t.Errorf("String(): got = %q, want = %q", got, want)
+	}
+}
+
+func TestFlagFunc_broken_value(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = FlagFunc(&register, "id", parseTestUUID)
+
+	args := []string{"--id", "not-a-uuid"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &FlagError{
+		Long: "id",
+		Err:  &ParseValueError{Type: "uuid", Err: ErrSyntax},
+	}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, got, want)
+	}
+}
+
+func TestFlagFunc_nil_parse(t *testing.T) {
+	var register DefaultRegister
+
+	_ = FlagFunc[int](&register, "count", nil)
+
+	want := &FlagError{Long: "count", Err: ErrNilTarget}
+	if err := register.Err(); !errors.Is(err, want) {
+		t.Errorf("Err(): got error = %v, want error = %v", err, want)
+	}
+}
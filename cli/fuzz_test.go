@@ -0,0 +1,81 @@
+//go:build go1.18
+// +build go1.18
+
+package cli
+
+import "testing"
+
+func fuzzParse(t *testing.T, register func(r Register), args []string) {
+	t.Helper()
+
+	var (
+		r      DefaultRegister
+		parser DefaultParser
+	)
+
+	register(&r)
+
+	defer func() {
+		if v := recover(); v != nil {
+			t.Fatalf("Parse(%q): panic: %v", args, v)
+		}
+	}()
+
+	_ = parser.Parse(nil, &r, args)
+}
+
+func FuzzParseFlags(f *testing.F) {
+	for _, v := range commonIntValues {
+		f.Add("--count", v.value)
+		f.Add("-c"+v.value, "")
+		f.Add("--count="+v.value, "--verbose")
+	}
+
+	for _, v := range commonBoolValues {
+		f.Add("--verbose="+v.value, "")
+		f.Add("-v", v.value)
+	}
+
+	for _, v := range commonIntBrokens {
+		f.Add("--count", v.value)
+		f.Add("-vc", v.value)
+	}
+
+	f.Add("--", "-")
+	f.Add("---", "=")
+	f.Add("-=", "--=")
+	f.Add("--no-verbose", "--verb")
+
+	f.Fuzz(func(t *testing.T, first, second string) {
+		fuzzParse(t, func(r Register) {
+			_ = Bool(r, "verbose", WithShort("v"))
+			_ = Int(r, "count", WithShort("c"))
+			_ = Strings(r, "tag", WithShort("t"))
+		}, []string{first, second})
+	})
+}
+
+func FuzzParseArgs(f *testing.F) {
+	for _, v := range commonIntValues {
+		f.Add(v.value, "")
+	}
+
+	for _, v := range commonBoolValues {
+		f.Add("1", v.value)
+	}
+
+	for _, v := range commonIntBrokens {
+		f.Add(v.value, v.value)
+	}
+
+	f.Add("--", "-1")
+	f.Add("-", "")
+
+	f.Fuzz(func(t *testing.T, first, second string) {
+		fuzzParse(t, func(r Register) {
+			_ = IntArg(r, "count")
+			_ = BoolArg(r, "flag", Optional)
+			_ = RestStrings(r, "rest")
+		}, []string{first, second})
+	})
+}
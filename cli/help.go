@@ -0,0 +1,617 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsHelp reports whether the err was caused by the --help flag.
+func IsHelp(err error) bool {
+	return errors.Is(err, ErrHelp)
+}
+
+// IsVersion reports whether the err was caused by the --version flag.
+func IsVersion(err error) bool {
+	return errors.Is(err, ErrVersion)
+}
+
+var (
+	_ Value    = (*helpValue)(nil)
+	_ boolFlag = (*helpValue)(nil)
+)
+
+// helpValue is a value of the --help flag registered by the DefaultParser.
+type helpValue struct {
+	boolValue
+}
+
+var (
+	_ Value    = (*versionValue)(nil)
+	_ boolFlag = (*versionValue)(nil)
+)
+
+// versionValue is a value of the --version flag registered by the
+// DefaultParser.
+type versionValue struct {
+	boolValue
+}
+
+// DefaultHelpFunc writes a usage of the last parsed register into the output
+// of the parser if it was set (see SetOutput) or the os.Stdout.
+func DefaultHelpFunc(p *DefaultParser) {
+	w := p.output
+	if w == nil {
+		w = os.Stdout
+	}
+
+	_ = p.FormatHelp(w)
+}
+
+// registerHelp adds the --help/-h flag into the register if it doesn't yet
+// have one. Names which are already used by the register are skipped.
+func (p *DefaultParser) registerHelp(r Register) error {
+	if _, ok := findHelpFlag(r); ok {
+		return nil
+	}
+
+	var long, short string
+	if _, ok := r.LongFlag("help"); !ok {
+		long = "help"
+	}
+
+	if _, ok := r.ShortFlag("h"); !ok {
+		short = "h"
+	}
+
+	if long == "" && short == "" {
+		return nil
+	}
+
+	return Var(r, new(helpValue), "",
+		WithLong(long),
+		WithShort(short),
+		Usage("Show help"),
+		commandFlag(true), // Skip required flags and args checks.
+	)
+}
+
+func findHelpFlag(r Register) (*Flag, bool) {
+	flags := r.Flags()
+	for i := range flags {
+		if _, ok := flags[i].Value.(*helpValue); ok {
+			return &flags[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func helpRequested(r Register) bool {
+	flag, ok := findHelpFlag(r)
+	return ok && bool(flag.Value.(*helpValue).boolValue)
+}
+
+// registerVersion adds the --version flag into the register if it doesn't
+// yet have one.
+func (p *DefaultParser) registerVersion(r Register) error {
+	if _, ok := findVersionFlag(r); ok {
+		return nil
+	}
+
+	if _, ok := r.LongFlag("version"); ok {
+		return nil
+	}
+
+	return Var(r, new(versionValue), "version",
+		Usage("Show version"),
+		commandFlag(true), // Skip required flags and args checks.
+	)
+}
+
+func findVersionFlag(r Register) (*Flag, bool) {
+	flags := r.Flags()
+	for i := range flags {
+		if _, ok := flags[i].Value.(*versionValue); ok {
+			return &flags[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func versionRequested(r Register) bool {
+	flag, ok := findVersionFlag(r)
+	return ok && bool(flag.Value.(*versionValue).boolValue)
+}
+
+// FormatHelp writes a usage block of the last parsed register into the w:
+// a synopsis line, a list of flags and a list of positional args.
+func (p *DefaultParser) FormatHelp(w io.Writer) error {
+	r := p.register
+	if r == nil {
+		r = &DefaultRegister{}
+	}
+
+	return p.formatHelp(w, r)
+}
+
+// FormatHelpOf writes a usage block of the register into the w (see
+// FormatHelp). It works before the Parse, e.g. in a top level --help
+// handler. Flags of the parser (e.g. --help) are registered in the register
+// as the Parse does.
+func (p *DefaultParser) FormatHelpOf(w io.Writer, r Register) error {
+	if err := p.prepareRegister(r); err != nil {
+		return err
+	}
+
+	return p.formatHelp(w, r)
+}
+
+func (p *DefaultParser) formatHelp(w io.Writer, r Register) error {
+	ew := easyWriter{w: w}
+
+	// Synopsis.
+	name := p.Name
+	if name == "" && len(os.Args) > 0 {
+		name = filepath.Base(os.Args[0])
+	}
+
+	ew.WriteString("usage: ")
+	ew.WriteString(name)
+
+	flags := visibleFlags(r.Flags())
+	if len(flags) > 0 {
+		ew.WriteString(" [flags]")
+	}
+
+	args := visibleArgs(r.Args())
+	for i := range args {
+		if args[i].Required() {
+			ew.Writef(" <%s>", args[i].Name)
+		} else {
+			ew.Writef(" [%s]", args[i].Name)
+		}
+	}
+
+	rest := r.Rest()
+	if rest != nil {
+		ew.Writef(" [%s...]", rest.Name)
+	}
+
+	ew.WriteString("\n")
+
+	if err := ew.Err(); err != nil {
+		return err
+	}
+
+	// Flags.
+	if len(flags) > 0 {
+		names, _ := flagColumns(p, flags)
+		width := columnsWidth(names)
+
+		// Ungrouped flags go first.
+		groups := append([]*FlagGroup{nil}, flagGroups(flags)...)
+		for _, group := range groups {
+			var groupFlags []Flag
+			for i := range flags {
+				if flags[i].Group == group {
+					groupFlags = append(groupFlags, flags[i])
+				}
+			}
+
+			if len(groupFlags) == 0 {
+				continue
+			}
+
+			if group == nil {
+				ew.WriteString("\nFlags:\n")
+			} else {
+				ew.Writef("\n%s:\n", group.Name)
+
+				if group.Description != "" {
+					ew.Writef("%s\n", group.Description)
+				}
+			}
+
+			if err := ew.Err(); err != nil {
+				return err
+			}
+
+			if err := writeFlags(w, p, groupFlags, width); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Args.
+	if len(args) > 0 || rest != nil {
+		ew.WriteString("\nArgs:\n")
+		if err := ew.Err(); err != nil {
+			return err
+		}
+
+		if err := writeArgs(w, args, rest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Groups returns groups of flags of the last parsed register in the order of
+// the first appearance (see WithFlagGroup).
+func (p *DefaultParser) Groups() []*FlagGroup {
+	if p.register == nil {
+		return nil
+	}
+
+	return flagGroups(visibleFlags(p.register.Flags()))
+}
+
+func flagGroups(flags []Flag) []*FlagGroup {
+	var groups []*FlagGroup
+	for i := range flags {
+		group := flags[i].Group
+		if group == nil {
+			continue
+		}
+
+		var found bool
+		for _, g := range groups {
+			if g == group {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups
+}
+
+// Synopsis returns a one-line usage of visible flags and args of the last
+// parsed register, e.g.:
+//
+//   --name <string> [--count <int>] [--verbose] <src> [<dst>] [<files>...]
+//
+// Optional flags and args are in brackets.
+func (p *DefaultParser) Synopsis() string {
+	if p.register == nil {
+		return ""
+	}
+
+	return p.synopsis(p.register)
+}
+
+// SynopsisOf returns a one-line usage of the register (see Synopsis). It
+// works before the Parse, see FormatHelpOf. Registration errors are reported
+// by the Parse.
+func (p *DefaultParser) SynopsisOf(r Register) string {
+	_ = p.prepareRegister(r)
+
+	return p.synopsis(r)
+}
+
+func (p *DefaultParser) synopsis(r Register) string {
+	var parts []string
+
+	flags := visibleFlags(r.Flags())
+	for i := range flags {
+		flag := &flags[i]
+
+		name := p.FormatLongFlag(flag.longForm())
+		if name == "" {
+			name = p.FormatShortFlag(flag.shortForm())
+		}
+
+		// Bool flags don't need a value.
+		if _, ok := flag.Value.(boolFlag); !ok {
+			name += " " + flagPlaceholder(flag)
+		}
+
+		if !flag.Required() {
+			name = "[" + name + "]"
+		}
+
+		parts = append(parts, name)
+	}
+
+	args := visibleArgs(r.Args())
+	for i := range args {
+		name := "<" + args[i].Name + ">"
+		if !args[i].Required() {
+			name = "[" + name + "]"
+		}
+
+		parts = append(parts, name)
+	}
+
+	if rest := r.Rest(); rest != nil {
+		name := "<" + rest.Name + ">..."
+		if rest.Min == 0 {
+			name = "[" + name + "]"
+		}
+
+		parts = append(parts, name)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// PrintDefaults writes visible flags of the last parsed register with their
+// usages and default values into the w, one flag per line:
+//
+//   -l, --log-level <string>  Log level (default: info)
+//
+// Usages which don't fit in 80 characters are moved to the next lines.
+func (p *DefaultParser) PrintDefaults(w io.Writer) error {
+	if p.register == nil {
+		return nil
+	}
+
+	return p.printDefaults(w, p.register)
+}
+
+// PrintDefaultsOf writes visible flags of the register with their usages and
+// default values into the w (see PrintDefaults). It works before the Parse,
+// see FormatHelpOf.
+func (p *DefaultParser) PrintDefaultsOf(w io.Writer, r Register) error {
+	if err := p.prepareRegister(r); err != nil {
+		return err
+	}
+
+	return p.printDefaults(w, r)
+}
+
+func (p *DefaultParser) printDefaults(w io.Writer, r Register) error {
+	flags := visibleFlags(r.Flags())
+	names, usages := flagColumns(p, flags)
+
+	ew := easyWriter{w: w}
+
+	var buf bytes.Buffer
+	for i, name := range names {
+		buf.Reset()
+		if usages[i] != nil {
+			if err := usages[i].Usage(nil, &buf); err != nil {
+				return err
+			}
+		}
+
+		line := "  " + name
+		usage := buf.String()
+
+		switch {
+		case usage == "":
+			ew.WriteString(line)
+
+		case len(line)+2+len(usage) <= printDefaultsWidth:
+			ew.WriteString(line)
+			ew.WriteString("  ")
+			ew.WriteString(usage)
+
+		default:
+			ew.WriteString(line)
+			ew.WriteString("\n")
+			ew.WriteString(wrapText(usage, printDefaultsIndent, printDefaultsWidth))
+		}
+
+		ew.WriteString("\n")
+	}
+
+	return ew.Err()
+}
+
+const (
+	printDefaultsWidth  = 80
+	printDefaultsIndent = "      "
+)
+
+// wrapText splits the text into lines by words. Every line starts with the
+// indent and is not longer than the width unless it has a single long word.
+func wrapText(text, indent string, width int) string {
+	var (
+		sb      strings.Builder
+		lineLen int
+	)
+	for _, word := range strings.Fields(text) {
+		switch {
+		case lineLen == 0:
+			sb.WriteString(indent)
+			lineLen = len(indent)
+
+		case lineLen+1+len(word) > width:
+			sb.WriteString("\n")
+			sb.WriteString(indent)
+			lineLen = len(indent)
+
+		default:
+			sb.WriteString(" ")
+			lineLen++
+		}
+
+		sb.WriteString(word)
+		lineLen += len(word)
+	}
+
+	return sb.String()
+}
+
+// writeFlags writes the flags with usages aligned by the longest name, but
+// not less than the width.
+func writeFlags(w io.Writer, p *DefaultParser, flags []Flag, width int) error {
+	names, usages := flagColumns(p, flags)
+	return writeColumns(w, names, usages, width)
+}
+
+func flagColumns(p *DefaultParser, flags []Flag) (names []string, usages []Usager) {
+	names = make([]string, len(flags))
+	usages = make([]Usager, len(flags))
+	for i := range flags {
+		flag := &flags[i]
+
+		var name string
+		if short := flag.shortForm(); short != "" {
+			name = p.FormatShortFlag(short)
+		}
+
+		if long := flag.longForm(); long != "" {
+			if name != "" {
+				name += ", "
+			}
+
+			name += p.FormatLongFlag(long)
+		}
+
+		// Bool flags don't need a value.
+		if _, ok := flag.Value.(boolFlag); !ok {
+			name += " " + flagPlaceholder(flag)
+		}
+
+		names[i] = name
+		if flag.Sensitive {
+			usages[i] = flag.Usage
+		} else {
+			usages[i] = withDefault(flag.Usage, flag.Default)
+		}
+	}
+
+	return names, usages
+}
+
+func writeArgs(w io.Writer, args []Arg, rest *RestArgs) error {
+	names := make([]string, 0, len(args)+1)
+	usages := make([]Usager, 0, len(args)+1)
+	for i := range args {
+		arg := &args[i]
+
+		name := arg.Name + " " + argPlaceholder(arg)
+
+		names = append(names, name)
+		usages = append(usages, withDefault(arg.Usage, arg.Default))
+	}
+
+	if rest != nil {
+		name := rest.Name + "... " + typePlaceholder(rest.Type())
+
+		names = append(names, name)
+		usages = append(usages, rest.Usage)
+	}
+
+	return writeColumns(w, names, usages, 0)
+}
+
+// defaultUsager appends the default value to the usage.
+type defaultUsager struct {
+	usage Usager
+	value string
+}
+
+func withDefault(usage Usager, getDefault func() (string, bool)) Usager {
+	v, empty := getDefault()
+	if empty || v == "" {
+		return usage
+	}
+
+	return defaultUsager{usage: usage, value: v}
+}
+
+func (u defaultUsager) Usage(cmd *Command, w io.Writer) error {
+	ew := easyWriter{w: w}
+
+	if u.usage != nil {
+		var buf bytes.Buffer
+		if err := u.usage.Usage(cmd, &buf); err != nil {
+			return err
+		}
+
+		if buf.Len() > 0 {
+			ew.Write(buf.Bytes())
+			ew.WriteString(" ")
+		}
+	}
+
+	ew.Writef("(default: %s)", u.value)
+
+	return ew.Err()
+}
+
+func flagPlaceholder(flag *Flag) string {
+	if flag.Placeholder != "" {
+		return "<" + flag.Placeholder + ">"
+	}
+
+	return typePlaceholder(flag.Type())
+}
+
+func argPlaceholder(arg *Arg) string {
+	if arg.Placeholder != "" {
+		return "<" + arg.Placeholder + ">"
+	}
+
+	return typePlaceholder(arg.Type())
+}
+
+// typePlaceholder returns a placeholder of a value in the help, e.g. <int>.
+// Values which don't implement the Typer are shown as <value>.
+func typePlaceholder(typ string) string {
+	if typ == "" {
+		typ = "value"
+	}
+
+	return "<" + typ + ">"
+}
+
+func columnsWidth(names []string) int {
+	var width int
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+
+	return width
+}
+
+// writeColumns writes the names with their usages aligned by the longest
+// name, but not less than the width.
+func writeColumns(w io.Writer, names []string, usages []Usager, width int) error {
+	maxLen := columnsWidth(names)
+	if width > maxLen {
+		maxLen = width
+	}
+
+	ew := easyWriter{w: w}
+
+	var buf bytes.Buffer
+	for i, name := range names {
+		ew.WriteString("  ")
+		ew.WriteString(name)
+
+		buf.Reset()
+		if usages[i] != nil {
+			if err := usages[i].Usage(nil, &buf); err != nil {
+				return err
+			}
+		}
+
+		if buf.Len() > 0 {
+			for j := len(name); j < maxLen+2; j++ {
+				ew.WriteString(" ")
+			}
+
+			ew.Write(buf.Bytes())
+		}
+
+		ew.WriteString("\n")
+	}
+
+	return ew.Err()
+}
@@ -0,0 +1,673 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParser_Parse_help(t *testing.T) {
+	tt := []struct {
+		name     string
+		args     []string
+		wantErr  error
+		wantHelp bool
+	}{
+		{
+			name:     "long",
+			args:     []string{"--help"},
+			wantErr:  ErrHelp,
+			wantHelp: true,
+		},
+		{
+			name:     "short",
+			args:     []string{"-h"},
+			wantErr:  ErrHelp,
+			wantHelp: true,
+		},
+		{
+			name:     "skip required",
+			args:     []string{"-v", "-h"},
+			wantErr:  ErrHelp,
+			wantHelp: true,
+		},
+		{
+			name:    "no help",
+			args:    []string{"--name", "test"},
+			wantErr: nil,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				called   *DefaultParser
+			)
+
+			parser := DefaultParser{
+				HelpFunc: func(p *DefaultParser) { called = p },
+			}
+
+			_ = Bool(&register, "v")
+			_ = String(&register, "name", Required)
+
+			err := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+
+			if (called != nil) != tc.wantHelp {
+				t.Errorf("Parse(%v): HelpFunc called = %v, want = %v", tc.args, called != nil, tc.wantHelp)
+			}
+
+			if called != nil && called != &parser {
+				t.Errorf("Parse(%v): HelpFunc was called with another parser", tc.args)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_help_disabled(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Bool(&register, "v")
+
+	args := []string{"--help"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &ParseFlagError{Name: "--help", Err: ErrUnknown}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, got, want)
+	}
+
+	if len(register.Flags()) != 1 {
+		t.Errorf("Flags(): got %d flags, want %d", len(register.Flags()), 1)
+	}
+}
+
+func TestParser_Parse_help_taken_names(t *testing.T) {
+	var (
+		register DefaultRegister
+		called   bool
+	)
+
+	parser := DefaultParser{
+		HelpFunc: func(p *DefaultParser) { called = true },
+	}
+
+	host := String(&register, "h")
+
+	args := []string{"-h", "localhost"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if called || *host != "localhost" {
+		t.Errorf("Parse(%v): got = (%v, %q), want = (%v, %q)", args, called, *host, false, "localhost")
+	}
+
+	// The second parse doesn't register the flag again.
+	if err := parser.Parse(nil, &register, []string{"--help"}); !errors.Is(err, ErrHelp) {
+		t.Fatalf("Parse(): got error = %v, want error = %v", err, ErrHelp)
+	}
+
+	if n := len(register.Flags()); n != 2 {
+		t.Errorf("Flags(): got %d flags, want %d", n, 2)
+	}
+}
+
+func TestWriteFlags(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		buf      strings.Builder
+	)
+
+	_ = Bool(&register, "verbose", WithShort("v"), Usage("Verbose output"))
+	_ = Int(&register, "n", Usage("Number"))
+	_ = String(&register, "name")
+
+	if err := writeFlags(&buf, &parser, register.Flags(), 0); err != nil {
+		t.Fatalf("writeFlags(): failed to write flags: %s", err)
+	}
+
+	want := "  -v, --verbose    Verbose output\n" +
+		"  -n <int>         Number\n" +
+		"  --name <string>\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeFlags(): got = %q, want = %q", got, want)
+	}
+}
+
+func TestParser_FormatHelp(t *testing.T) {
+	var (
+		register DefaultRegister
+		buf      strings.Builder
+	)
+
+	parser := DefaultParser{
+		Name:     "test",
+		HelpFunc: func(p *DefaultParser) {},
+	}
+
+	_ = Bool(&register, "verbose", WithShort("v"), Usage("Verbose output"))
+	_ = Int(&register, "count", Usage("Number of runs"))
+	_ = StringArg(&register, "src", Usage("Source file"))
+	_ = StringArg(&register, "dst", Optional)
+	_ = RestStrings(&register, "files", Usage("Other files"))
+
+	if err := parser.Parse(nil, &register, []string{"a"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if err := parser.FormatHelp(&buf); err != nil {
+		t.Fatalf("FormatHelp(): failed to format help: %s", err)
+	}
+
+	want := "usage: test [flags] <src> [dst] [files...]\n" +
+		"\n" +
+		"Flags:\n" +
+		"  -v, --verbose  Verbose output\n" +
+		"  --count <int>  Number of runs\n" +
+		"  -h, --help     Show help\n" +
+		"\n" +
+		"Args:\n" +
+		"  src <string>         Source file\n" +
+		"  dst <string>\n" +
+		"  files... <[]string>  Other files\n"
+	if got := buf.String(); got != want {
+		t.Errorf("FormatHelp(): got = %q, want = %q", got, want)
+	}
+}
+
+// upperDefaultValue shows its default value in upper case.
+type upperDefaultValue struct {
+	untypedValue
+}
+
+func (v *upperDefaultValue) Default() string { return strings.ToUpper(v.value) }
+
+func TestParser_FormatHelp_defaults(t *testing.T) {
+	var (
+		register DefaultRegister
+		buf      strings.Builder
+	)
+
+	parser := DefaultParser{Name: "test"}
+
+	host := "localhost"
+
+	_ = Int(&register, "port", WithDefault("8080"), Usage("Port"))
+	_ = StringVar(&register, &host, "host")
+	_ = Bool(&register, "v", Usage("Verbose output"))
+	_ = Int(&register, "n", Usage("Number"))
+	_ = Strings(&register, "tag")
+	_ = Var(&register, &upperDefaultValue{untypedValue{value: "info"}}, "level", WithChoices("info", "debug"))
+	_ = StringArg(&register, "file", Optional)
+
+	if err := parser.Parse(nil, &register, []string{"--host", "example.com", "--port", "80"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if err := parser.FormatHelp(&buf); err != nil {
+		t.Fatalf("FormatHelp(): failed to format help: %s", err)
+	}
+
+	want := "usage: test [flags] [file]\n" +
+		"\n" +
+		"Flags:\n" +
+		"  --port <int>      Port (default: 8080)\n" +
+		"  --host <string>   (default: localhost)\n" +
+		"  -v                Verbose output\n" +
+		"  -n <int>          Number\n" +
+		"  --tag <[]string>\n" +
+		"  --level <value>   (default: INFO)\n" +
+		"\n" +
+		"Args:\n" +
+		"  file <string>\n"
+	if got := buf.String(); got != want {
+		t.Errorf("FormatHelp(): got = %q, want = %q", got, want)
+	}
+}
+
+// untypedValue is a custom value without the Typer.
+type untypedValue struct {
+	value string
+}
+
+func (v *untypedValue) String() string { return v.value }
+
+func (v *untypedValue) Set(s string) error {
+	v.value = s
+	return nil
+}
+
+func TestWriteFlags_types(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		buf      strings.Builder
+	)
+
+	_ = Count(&register, "v")
+	_ = Duration(&register, "timeout")
+	_ = Var(&register, &untypedValue{}, "custom")
+
+	if err := writeFlags(&buf, &parser, register.Flags(), 0); err != nil {
+		t.Fatalf("writeFlags(): failed to write flags: %s", err)
+	}
+
+	want := "  -v\n" +
+		"  --timeout <time.Duration>\n" +
+		"  --custom <value>\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeFlags(): got = %q, want = %q", got, want)
+	}
+}
+
+type errorWriter struct {
+	err error
+}
+
+func (w errorWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestParser_FormatHelp_writer_error(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Bool(&register, "v")
+
+	if err := parser.Parse(nil, &register, nil); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	want := errors.New("broken writer")
+	if got := parser.FormatHelp(errorWriter{err: want}); got != want {
+		t.Errorf("FormatHelp(): got error = %v, want error = %v", got, want)
+	}
+}
+
+func TestParser_Parse_version(t *testing.T) {
+	var (
+		register DefaultRegister
+		called   bool
+	)
+
+	parser := DefaultParser{
+		HelpFunc:    func(p *DefaultParser) { t.Errorf("HelpFunc(): unexpected call") },
+		VersionFunc: func(p *DefaultParser) { called = true },
+	}
+
+	_ = String(&register, "name", Required)
+
+	args := []string{"--version"}
+
+	err := parser.Parse(nil, &register, args)
+	if !errors.Is(err, ErrVersion) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, err, ErrVersion)
+	}
+
+	if !called {
+		t.Errorf("Parse(%v): VersionFunc was not called", args)
+	}
+}
+
+func TestIsHelp(t *testing.T) {
+	tt := []struct {
+		err         error
+		wantHelp    bool
+		wantVersion bool
+	}{
+		{err: nil},
+		{err: ErrUnknown},
+		{err: ErrHelp, wantHelp: true},
+		{err: ErrVersion, wantVersion: true},
+		{err: &CommandError{Err: ErrHelp}, wantHelp: true},
+	}
+
+	for _, tc := range tt {
+		if got := IsHelp(tc.err); got != tc.wantHelp {
+			t.Errorf("IsHelp(%v): got = %v, want = %v", tc.err, got, tc.wantHelp)
+		}
+
+		if got := IsVersion(tc.err); got != tc.wantVersion {
+			t.Errorf("IsVersion(%v): got = %v, want = %v", tc.err, got, tc.wantVersion)
+		}
+	}
+}
+
+func TestParser_FormatHelp_hidden(t *testing.T) {
+	var (
+		register DefaultRegister
+		buf      strings.Builder
+	)
+
+	parser := DefaultParser{Name: "test"}
+
+	_ = Bool(&register, "v", Usage("Verbose output"))
+	_ = Bool(&register, "debug-internals", WithHidden(), Usage("Debug"))
+	_ = StringArg(&register, "src")
+	_ = StringArg(&register, "secret", WithHiddenArg(), Optional)
+
+	if err := parser.Parse(nil, &register, []string{"a"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if err := parser.FormatHelp(&buf); err != nil {
+		t.Fatalf("FormatHelp(): failed to format help: %s", err)
+	}
+
+	want := "usage: test [flags] <src>\n" +
+		"\n" +
+		"Flags:\n" +
+		"  -v  Verbose output\n" +
+		"\n" +
+		"Args:\n" +
+		"  src <string>\n"
+	if got := buf.String(); got != want {
+		t.Errorf("FormatHelp(): got = %q, want = %q", got, want)
+	}
+}
+
+func TestParser_FormatHelp_short_long_only(t *testing.T) {
+	var (
+		register DefaultRegister
+		buf      strings.Builder
+	)
+
+	parser := DefaultParser{Name: "test"}
+
+	_ = Bool(&register, "verbose", WithShort("v"), WithShortOnly(), Usage("Verbose output"))
+	_ = Bool(&register, "force", WithShort("f"), WithLongOnly(), Usage("Force"))
+
+	if err := parser.Parse(nil, &register, nil); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if err := parser.FormatHelp(&buf); err != nil {
+		t.Fatalf("FormatHelp(): failed to format help: %s", err)
+	}
+
+	want := "usage: test [flags]\n" +
+		"\n" +
+		"Flags:\n" +
+		"  -v       Verbose output\n" +
+		"  --force  Force\n"
+	if got := buf.String(); got != want {
+		t.Errorf("FormatHelp(): got = %q, want = %q", got, want)
+	}
+
+	if got, want := parser.Synopsis(), "[-v] [--force]"; got != want {
+		t.Errorf("Synopsis(): got = %q, want = %q", got, want)
+	}
+}
+
+func TestParser_FormatHelp_groups(t *testing.T) {
+	var (
+		register DefaultRegister
+		buf      strings.Builder
+	)
+
+	parser := DefaultParser{Name: "test"}
+
+	output := &FlagGroup{Name: "Output", Description: "Format of the output."}
+	network := &FlagGroup{Name: "Network"}
+
+	_ = Bool(&register, "json", WithFlagGroup(output), Usage("JSON output"))
+	_ = Int(&register, "port", WithFlagGroup(network), Usage("Port"))
+	_ = Bool(&register, "v", Usage("Verbose output"))
+	_ = Bool(&register, "yaml", WithFlagGroup(output), Usage("YAML output"))
+
+	if err := parser.Parse(nil, &register, nil); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if got, want := parser.Groups(), []*FlagGroup{output, network}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Groups(): got = %v, want = %v", got, want)
+	}
+
+	if err := parser.FormatHelp(&buf); err != nil {
+		t.Fatalf("FormatHelp(): failed to format help: %s", err)
+	}
+
+	want := "usage: test [flags]\n" +
+		"\n" +
+		"Flags:\n" +
+		"  -v            Verbose output\n" +
+		"\n" +
+		"Output:\n" +
+		"Format of the output.\n" +
+		"  --json        JSON output\n" +
+		"  --yaml        YAML output\n" +
+		"\n" +
+		"Network:\n" +
+		"  --port <int>  Port\n"
+	if got := buf.String(); got != want {
+		t.Errorf("FormatHelp(): got = %q, want = %q", got, want)
+	}
+}
+
+func TestParser_PrintDefaults(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		buf      strings.Builder
+	)
+
+	_ = String(&register, "log-level", WithShort("l"), WithDefault("info"), Usage("Log level"))
+	_ = Bool(&register, "verbose", Usage("Verbose output"))
+	_ = Bool(&register, "secret", WithHidden())
+	_ = Int(&register, "n")
+	_ = String(&register, "output-format",
+		Usage("Format of the output. Supported formats are json, yaml and text, the text format is "+
+			"the default one"),
+	)
+
+	want := "  -l, --log-level <string>  Log level (default: info)\n" +
+		"  --verbose  Verbose output\n" +
+		"  -n <int>\n" +
+		"  --output-format <string>\n" +
+		"      Format of the output. Supported formats are json, yaml and text, the text\n" +
+		"      format is the default one\n"
+
+	// Before the Parse, e.g. in a top level --help handler.
+	if err := parser.PrintDefaultsOf(&buf, &register); err != nil {
+		t.Fatalf("PrintDefaultsOf(): failed to print defaults: %s", err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Errorf("PrintDefaultsOf(): got = %q, want = %q", got, want)
+	}
+
+	buf.Reset()
+
+	if err := parser.Parse(nil, &register, nil); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if err := parser.PrintDefaults(&buf); err != nil {
+		t.Fatalf("PrintDefaults(): failed to print defaults: %s", err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Errorf("PrintDefaults(): got = %q, want = %q", got, want)
+	}
+}
+
+func TestParser_FormatHelpOf_before_parse(t *testing.T) {
+	var (
+		register DefaultRegister
+		buf      strings.Builder
+	)
+
+	parser := DefaultParser{Name: "test", HelpFunc: DefaultHelpFunc}
+
+	_ = Int(&register, "port", WithDefault("8080"), Usage("Port"))
+	_ = StringArg(&register, "src")
+
+	if err := parser.FormatHelpOf(&buf, &register); err != nil {
+		t.Fatalf("FormatHelpOf(): failed to format help: %s", err)
+	}
+
+	want := "usage: test [flags] <src>\n" +
+		"\n" +
+		"Flags:\n" +
+		"  --port <int>  Port (default: 8080)\n" +
+		"  -h, --help    Show help\n" +
+		"\n" +
+		"Args:\n" +
+		"  src <string>\n"
+	if got := buf.String(); got != want {
+		t.Errorf("FormatHelpOf(): got = %q, want = %q", got, want)
+	}
+
+	if got, want := parser.SynopsisOf(&register), "[--port <int>] [--help] <src>"; got != want {
+		t.Errorf("SynopsisOf(): got = %q, want = %q", got, want)
+	}
+
+	// The register must stay parsable after the help.
+	args := []string{"--port", "80", "a"}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tt := []struct {
+		text string
+		want string
+	}{
+		{text: "", want: ""},
+		{text: "one two", want: "  one two"},
+		{text: "one two three", want: "  one two\n  three"},
+		{text: "  one   two  ", want: "  one two"},
+		{text: "looooooooong one", want: "  looooooooong\n  one"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.text, func(t *testing.T) {
+			if got := wrapText(tc.text, "  ", 10); got != tc.want {
+				t.Errorf("wrapText(%q): got = %q, want = %q", tc.text, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParser_Synopsis(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	if got := parser.Synopsis(); got != "" {
+		t.Errorf("Synopsis(): got = %q, want empty synopsis", got)
+	}
+
+	_ = String(&register, "name", Required)
+	_ = Int(&register, "count")
+	_ = Bool(&register, "verbose")
+	_ = Bool(&register, "v")
+	_ = Bool(&register, "secret", WithHidden())
+	_ = StringArg(&register, "src")
+	_ = StringArg(&register, "dst", Optional)
+	_ = RestStrings(&register, "files")
+
+	if err := parser.Parse(nil, &register, []string{"--name", "test", "a"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	want := "--name <string> [--count <int>] [--verbose] [-v] <src> [<dst>] [<files>...]"
+	if got := parser.Synopsis(); got != want {
+		t.Errorf("Synopsis(): got = %q, want = %q", got, want)
+	}
+}
+
+func TestParser_FormatHelp_placeholder(t *testing.T) {
+	var (
+		register DefaultRegister
+		buf      strings.Builder
+	)
+
+	parser := DefaultParser{Name: "test"}
+
+	_ = Int(&register, "port", WithPlaceholder("port"), Usage("Port"))
+	_ = String(&register, "host", WithPlaceholder("host"), WithPlaceholder("addr"))
+	_ = StringArg(&register, "config", WithArgPlaceholder("path"))
+
+	if err := parser.Parse(nil, &register, []string{"config.json"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if err := parser.FormatHelp(&buf); err != nil {
+		t.Fatalf("FormatHelp(): failed to format help: %s", err)
+	}
+
+	want := "usage: test [flags] <config>\n" +
+		"\n" +
+		"Flags:\n" +
+		"  --port <port>  Port\n" +
+		"  --host <addr>\n" +
+		"\n" +
+		"Args:\n" +
+		"  config <path>\n"
+	if got := buf.String(); got != want {
+		t.Errorf("FormatHelp(): got = %q, want = %q", got, want)
+	}
+
+	buf.Reset()
+
+	if err := parser.PrintDefaults(&buf); err != nil {
+		t.Fatalf("PrintDefaults(): failed to print defaults: %s", err)
+	}
+
+	want = "  --port <port>  Port\n" +
+		"  --host <addr>\n"
+	if got := buf.String(); got != want {
+		t.Errorf("PrintDefaults(): got = %q, want = %q", got, want)
+	}
+}
+
+func TestParser_FormatHelp_usage_options(t *testing.T) {
+	var (
+		register DefaultRegister
+		buf      strings.Builder
+	)
+
+	parser := DefaultParser{Name: "test"}
+
+	_ = Bool(&register, "verbose", WithFlagUsage("Verbose output"))
+	_ = Bool(&register, "dry", WithUsage(Usage("Dry run")))
+	_ = Bool(&register, "quiet", Usage("Quiet"), WithFlagUsage(""))
+	_ = StringArg(&register, "file", WithArgUsage("Input file"))
+
+	if err := parser.Parse(nil, &register, []string{"in.txt"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if err := parser.FormatHelp(&buf); err != nil {
+		t.Fatalf("FormatHelp(): failed to format help: %s", err)
+	}
+
+	want := "usage: test [flags] <file>\n" +
+		"\n" +
+		"Flags:\n" +
+		"  --verbose  Verbose output\n" +
+		"  --dry      Dry run\n" +
+		"  --quiet    Quiet\n" +
+		"\n" +
+		"Args:\n" +
+		"  file <string>  Input file\n"
+	if got := buf.String(); got != want {
+		t.Errorf("FormatHelp(): got = %q, want = %q", got, want)
+	}
+}
@@ -48,9 +48,9 @@ func (h DefaultHelper) Help(cmd *Command, w io.Writer) error {
 	ew := easyWriter{w: w}
 
 	path := cmd.Path()
-	args := cmd.Args()
+	args := visibleArgs(cmd.Args())
 	rest := cmd.Rest()
-	flags := cmd.Flags()
+	flags := withAllowedForms(cmd.VisibleFlags())
 
 	// Usage with argumens.
 	ew.Writef("Usage:")
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"net"
+)
+
+// net.IP
+
+var (
+	_ Value   = (*ipValue)(nil)
+	_ Getter  = (*ipValue)(nil)
+	_ Emptier = (*ipValue)(nil)
+	_ Typer   = (*ipValue)(nil)
+)
+
+type ipValue net.IP
+
+func newIPValue(p *net.IP) *ipValue {
+	return (*ipValue)(p)
+}
+
+func (v *ipValue) Set(s string) error {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return &ParseValueError{
+			Type:  "ip",
+			Err:   ErrSyntax,
+			Input: s,
+		}
+	}
+
+	*v = ipValue(ip)
+	return nil
+}
+
+func (v *ipValue) Get() interface{} { return net.IP(*v) }
+
+func (v *ipValue) Empty() bool { return len(*v) == 0 }
+
+func (v *ipValue) String() string {
+	if len(*v) == 0 {
+		return ""
+	}
+
+	return net.IP(*v).String()
+}
+
+func (*ipValue) Type() string { return "ip" }
+
+// IPVar defines a net.IP flag with specified name. Both IPv4 ("192.168.1.1")
+// and IPv6 ("::1") addresses are accepted.
+// The argument p points to a net.IP variable in which to store the value of the flag.
+//
+// Options are the same as for the cli.StringVar.
+func IPVar(register Register, p *net.IP, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newIPValue(p), name, options...)
+}
+
+// IP defines a net.IP flag with specified name (see cli.IPVar).
+// The return value is the address of a net.IP variable that stores the value of the flag.
+func IP(register Register, name string, options ...FlagOptionApplyer) *net.IP {
+	p := new(net.IP)
+	_ = IPVar(register, p, name, options...)
+	return p
+}
+
+// IPArgVar defines a net.IP argument with specified name (see cli.IPVar).
+// The argument p points to a net.IP variable in which to store the value of the argument.
+func IPArgVar(register Register, p *net.IP, name string, options ...ArgOptionApplyer) error {
+	return ArgVar(register, newIPValue(p), name, options...)
+}
+
+// IPArg defines a net.IP argument with specified name (see cli.IPVar).
+// The return value is the address of a net.IP variable that stores the value of the argument.
+func IPArg(register Register, name string, options ...ArgOptionApplyer) *net.IP {
+	p := new(net.IP)
+	_ = IPArgVar(register, p, name, options...)
+	return p
+}
+
+// net.IPNet
+
+var (
+	_ Value   = (*cidrValue)(nil)
+	_ Getter  = (*cidrValue)(nil)
+	_ Emptier = (*cidrValue)(nil)
+	_ Typer   = (*cidrValue)(nil)
+)
+
+type cidrValue net.IPNet
+
+func newCIDRValue(p *net.IPNet) *cidrValue {
+	return (*cidrValue)(p)
+}
+
+func (v *cidrValue) Set(s string) error {
+	_, ipnet, err := net.ParseCIDR(s)
+	if err != nil {
+		return &ParseValueError{
+			Type:  "cidr",
+			Err:   ErrSyntax,
+			Input: s,
+		}
+	}
+
+	*v = cidrValue(*ipnet)
+	return nil
+}
+
+func (v *cidrValue) Get() interface{} { return net.IPNet(*v) }
+
+func (v *cidrValue) Empty() bool { return len(v.IP) == 0 }
+
+func (v *cidrValue) String() string {
+	if len(v.IP) == 0 {
+		return ""
+	}
+
+	return (*net.IPNet)(v).String()
+}
+
+func (*cidrValue) Type() string { return "cidr" }
+
+// CIDRVar defines a net.IPNet flag with specified name for subnets in
+// the CIDR notation (e.g. "10.0.0.0/24"). The flag stores the network, so
+// "10.0.0.1/24" is 10.0.0.0/24.
+// The argument p points to a net.IPNet variable in which to store the value of the flag.
+//
+// Options are the same as for the cli.StringVar.
+func CIDRVar(register Register, p *net.IPNet, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newCIDRValue(p), name, options...)
+}
+
+// CIDR defines a net.IPNet flag with specified name (see cli.CIDRVar).
+// The return value is the address of a net.IPNet variable that stores the value of the flag.
+func CIDR(register Register, name string, options ...FlagOptionApplyer) *net.IPNet {
+	p := new(net.IPNet)
+	_ = CIDRVar(register, p, name, options...)
+	return p
+}
+
+// CIDRArgVar defines a net.IPNet argument with specified name (see cli.CIDRVar).
+// The argument p points to a net.IPNet variable in which to store the value of the argument.
+func CIDRArgVar(register Register, p *net.IPNet, name string, options ...ArgOptionApplyer) error {
+	return ArgVar(register, newCIDRValue(p), name, options...)
+}
+
+// CIDRArg defines a net.IPNet argument with specified name (see cli.CIDRVar).
+// The return value is the address of a net.IPNet variable that stores the value of the argument.
+func CIDRArg(register Register, name string, options ...ArgOptionApplyer) *net.IPNet {
+	p := new(net.IPNet)
+	_ = CIDRArgVar(register, p, name, options...)
+	return p
+}
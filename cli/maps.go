@@ -0,0 +1,67 @@
+package cli
+
+import "strings"
+
+// map[string]string
+
+var (
+	_ Value   = (*stringMapValue)(nil)
+	_ Getter  = (*stringMapValue)(nil)
+	_ Emptier = (*stringMapValue)(nil)
+	_ Typer   = (*stringMapValue)(nil)
+)
+
+type stringMapValue map[string]string
+
+func newStringMapValue(p *map[string]string) *stringMapValue {
+	return (*stringMapValue)(p)
+}
+
+func (v *stringMapValue) Set(val string) error {
+	idx := strings.IndexByte(val, '=')
+	if idx == -1 {
+		return &ParseValueError{Type: "stringmap", Err: ErrSyntax, Input: val}
+	}
+
+	if *v == nil {
+		*v = make(stringMapValue)
+	}
+
+	// Last writer wins.
+	(*v)[val[:idx]] = val[idx+1:]
+
+	return nil
+}
+
+func (v *stringMapValue) Get() interface{} { return map[string]string(*v) }
+
+func (v *stringMapValue) Empty() bool { return len(*v) == 0 }
+
+func (v *stringMapValue) String() string {
+	if *v == nil {
+		return "{}"
+	}
+
+	return jsonString(map[string]string(*v))
+}
+
+func (*stringMapValue) Type() string { return "stringmap" }
+
+// StringMapVar defines a map[string]string flag with specified name.
+// Every occurrence of the flag adds a KEY=VALUE pair to the map:
+// "--label app=web --label env=prod" is map[string]string{"app": "web", "env": "prod"}.
+// The value is split on the first "=", duplicate keys overwrite previous values.
+// The argument p points to a map[string]string variable in which to store values of the flag.
+//
+// Options are the same as for the cli.StringVar.
+func StringMapVar(register Register, p *map[string]string, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newStringMapValue(p), name, options...)
+}
+
+// StringMap defines a map[string]string flag with specified name (see cli.StringMapVar).
+// The return value is the address of a map[string]string variable that stores values of the flag.
+func StringMap(register Register, name string, options ...FlagOptionApplyer) *map[string]string {
+	p := new(map[string]string)
+	_ = StringMapVar(register, p, name, options...)
+	return p
+}
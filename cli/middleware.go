@@ -0,0 +1,51 @@
+package cli
+
+// Middleware wraps every value set in the parser.
+//
+// The name is a long or short name of a flag, or a name of an arg. A middleware
+// must call the next to continue the chain, it may pass a modified value to
+// the next or skip the call to drop the value.
+//
+//   parser.UseMiddleware(func(name, value string, next func(string) error) error {
+//       log.Printf("%s = %s", name, value)
+//       return next(value)
+//   })
+type Middleware func(name string, value string, next func(string) error) error
+
+// UseMiddleware adds the middleware to the end of the chain.
+// The first added middleware is called first.
+func (p *DefaultParser) UseMiddleware(m Middleware) {
+	if m != nil {
+		p.middlewares = append(p.middlewares, m)
+	}
+}
+
+type setter interface {
+	Set(string) error
+}
+
+var _ setter = (*restSetter)(nil)
+
+type restSetter RestArgs
+
+func (rs *restSetter) Set(val string) error {
+	return (*RestArgs)(rs).Add(val)
+}
+
+func (p *DefaultParser) set(name, value string, s setter) error {
+	if len(p.middlewares) == 0 {
+		return s.Set(value)
+	}
+
+	return p.callMiddleware(0, name, value, s)
+}
+
+func (p *DefaultParser) callMiddleware(i int, name, value string, s setter) error {
+	if i >= len(p.middlewares) {
+		return s.Set(value)
+	}
+
+	return p.middlewares[i](name, value, func(v string) error {
+		return p.callMiddleware(i+1, name, v, s)
+	})
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultParser_UseMiddleware(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		calls    []string
+	)
+
+	parser.UseMiddleware(func(name, value string, next func(string) error) error {
+		calls = append(calls, "first:"+name+"="+value)
+		return next(strings.TrimSpace(value))
+	})
+
+	parser.UseMiddleware(func(name, value string, next func(string) error) error {
+		calls = append(calls, "second:"+name+"="+value)
+		return next(strings.ToUpper(value))
+	})
+
+	level := String(&register, "level")
+	count := Int(&register, "c")
+	user := StringArg(&register, "user")
+	rest := RestStrings(&register, "rest")
+
+	args := []string{"--level", " debug ", "-c", "10", "root", "a"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	const (
+		wantLevel = "DEBUG"
+		wantCount = 10
+		wantUser  = "ROOT"
+	)
+
+	if *level != wantLevel {
+		t.Errorf("Parse(): level: got = %q, want = %q", *level, wantLevel)
+	}
+
+	if *count != wantCount {
+		t.Errorf("Parse(): count: got = %v, want = %v", *count, wantCount)
+	}
+
+	if *user != wantUser {
+		t.Errorf("Parse(): user: got = %q, want = %q", *user, wantUser)
+	}
+
+	wantRest := []string{"A"}
+	if !reflect.DeepEqual(*rest, wantRest) {
+		t.Errorf("Parse(): rest: got = %#v, want = %#v", *rest, wantRest)
+	}
+
+	wantCalls := []string{
+		"first:level= debug ", "second:level=debug",
+		"first:c=10", "second:c=10",
+		"first:user=root", "second:user=root",
+		"first:rest=a", "second:rest=a",
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("Parse(): calls: got = %#v, want = %#v", calls, wantCalls)
+	}
+}
+
+func TestDefaultParser_UseMiddleware_error(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	errMiddleware := errors.New("middleware error")
+
+	parser.UseMiddleware(func(name, value string, next func(string) error) error {
+		if name == "password" {
+			return errMiddleware
+		}
+
+		return next(value)
+	})
+
+	_ = String(&register, "password")
+
+	got := parser.Parse(nil, &register, []string{"--password", "secret"})
+	want := &FlagError{Long: "password", Err: errMiddleware}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+	}
+}
@@ -1,5 +1,10 @@
 package cli
 
+import (
+	"strconv"
+	"time"
+)
+
 // Option interfaces.
 
 type FlagOptionApplyer interface {
@@ -38,6 +43,18 @@ func (fn RestOptionFunc) RestOptionApply(o *RestOptions) {
 	fn(o)
 }
 
+type ParserOptionApplyer interface {
+	ParserOptionApply(*DefaultParser)
+}
+
+var _ ParserOptionApplyer = (ParserOptionFunc)(nil)
+
+type ParserOptionFunc func(*DefaultParser)
+
+func (fn ParserOptionFunc) ParserOptionApply(p *DefaultParser) {
+	fn(p)
+}
+
 // Common options.
 
 var (
@@ -77,6 +94,23 @@ func (opt Necessary) ArgOptionApply(o *ArgOptions) {
 	o.Necessary = opt
 }
 
+// WithRequired makes a flag or an arg required. It's the same as passing
+// the cli.Required.
+//
+//   _ = cli.String(register, "name", cli.WithRequired())
+func WithRequired() Necessary {
+	return Required
+}
+
+// WithOptional makes a flag or an arg optional. It's the same as passing
+// the cli.Optional. Args are required by default, so it's mostly useful for
+// them.
+//
+//   _ = cli.StringArg(register, "name", cli.WithOptional())
+func WithOptional() Necessary {
+	return Optional
+}
+
 // Usage option.
 
 var (
@@ -163,20 +197,52 @@ func WithUsage(u Usager) UsageOption {
 	return usager{u}
 }
 
+// WithFlagUsage sets the usage of the flag. It's the same as
+// Usage(s) but cannot be applied to args by mistake.
+func WithFlagUsage(s string) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		Usage(s).FlagOptionApply(o)
+	}
+}
+
+// WithArgUsage sets the usage of the arg. It's the same as Usage(s) but
+// cannot be applied to flags by mistake.
+func WithArgUsage(s string) ArgOptionFunc {
+	return func(o *ArgOptions) {
+		Usage(s).ArgOptionApply(o)
+	}
+}
+
 // Flag options.
 
 var _ FlagOptionApplyer = FlagOptions{}
 
 type FlagOptions struct {
-	Value     Value
-	Short     string
-	Long      string
-	Usage     Usager
-	Necessary Necessary // Optional if unset
-
-	commandFlag bool
-
-	// Global bool // TODO
+	Value      Value
+	Short      string
+	Long       string
+	Usage      Usager
+	Necessary  Necessary // Optional if unset
+	Env        string
+	Choices    []string
+	Deprecated string
+	Group      *FlagGroup
+	Hidden     bool
+	Persistent bool
+	Sensitive  bool
+	ShortOnly  bool
+	LongOnly   bool
+
+	Placeholder string
+	Annotations map[string]string
+
+	defaultValue    *string // Set via Value.Set at the registration.
+	filePrefix      *string // Prefix of file paths for the FileString.
+	validators      validators
+	transforms      transforms
+	caseFoldChoices bool
+	commandFlag     bool
+	refs            []*FlagRef // Filled after the registration.
 }
 
 func (o FlagOptions) FlagOptionApply(opts *FlagOptions) {
@@ -198,7 +264,60 @@ func (o FlagOptions) FlagOptionApply(opts *FlagOptions) {
 
 	opts.Necessary = o.Necessary
 
+	if o.Env != "" {
+		opts.Env = o.Env
+	}
+
+	if o.defaultValue != nil {
+		opts.defaultValue = o.defaultValue
+	}
+
+	if o.Choices != nil {
+		opts.Choices = o.Choices
+	}
+
+	if o.Deprecated != "" {
+		opts.Deprecated = o.Deprecated
+	}
+
+	if o.Group != nil {
+		opts.Group = o.Group
+	}
+
+	opts.Hidden = opts.Hidden || o.Hidden
+	opts.Persistent = opts.Persistent || o.Persistent
+	opts.Sensitive = opts.Sensitive || o.Sensitive
+	opts.ShortOnly = opts.ShortOnly || o.ShortOnly
+	opts.LongOnly = opts.LongOnly || o.LongOnly
+
+	if o.Placeholder != "" {
+		opts.Placeholder = o.Placeholder
+	}
+
+	for key, value := range o.Annotations {
+		opts.annotate(key, value)
+	}
+
+	opts.validators = append(opts.validators, o.validators...)
+	opts.transforms = append(opts.transforms, o.transforms...)
+
+	opts.caseFoldChoices = opts.caseFoldChoices || o.caseFoldChoices
+
+	if o.filePrefix != nil {
+		opts.filePrefix = o.filePrefix
+	}
+
 	opts.commandFlag = o.commandFlag
+
+	opts.refs = append(opts.refs, o.refs...)
+}
+
+func (o *FlagOptions) annotate(key, value string) {
+	if o.Annotations == nil {
+		o.Annotations = make(map[string]string)
+	}
+
+	o.Annotations[key] = value
 }
 
 func (o *FlagOptions) applyName(name string) {
@@ -224,12 +343,195 @@ func WithShort(name string) FlagOptionFunc {
 	}
 }
 
+// WithShorthand sets the short name of the flag from the rune. Runes which
+// are not a single byte (e.g. 'ü') are invalid short names, and the
+// registration fails with the ErrInvalidName.
+//
+//   _ = cli.Bool(register, "verbose", cli.WithShorthand('v'))
+func WithShorthand(r rune) FlagOptionFunc {
+	return WithShort(string(r))
+}
+
+// WithLong sets the long name of the flag. It's useful with an empty or short
+// name of the flag. The registration fails with the ErrDuplicate wrapping the
+// ErrInvalidName if the name of the flag is long too.
+//
+//   _ = cli.Bool(register, "v", cli.WithLong("verbose"))
 func WithLong(name string) FlagOptionFunc {
 	return func(o *FlagOptions) {
 		o.Long = name
 	}
 }
 
+// WithDefault sets the default value of the flag. The value is passed to the
+// Value.Set at the registration, so it's parsed the same way as a value from
+// the command line and a parse error is returned by the cli.Var.
+//
+//   _ = cli.Int(register, "port", cli.WithDefault("8080"))
+func WithDefault(value string) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.defaultValue = &value
+	}
+}
+
+// WithDefaultBool sets the default value of a bool flag (see cli.WithDefault).
+func WithDefaultBool(v bool) FlagOptionFunc {
+	return WithDefault(strconv.FormatBool(v))
+}
+
+// WithDefaultInt sets the default value of an int flag (see cli.WithDefault).
+func WithDefaultInt(v int) FlagOptionFunc {
+	return WithDefault(strconv.Itoa(v))
+}
+
+// WithDefaultInt64 sets the default value of an int64 flag (see cli.WithDefault).
+func WithDefaultInt64(v int64) FlagOptionFunc {
+	return WithDefault(strconv.FormatInt(v, 10))
+}
+
+// WithDefaultUint sets the default value of an uint flag (see cli.WithDefault).
+func WithDefaultUint(v uint) FlagOptionFunc {
+	return WithDefault(strconv.FormatUint(uint64(v), 10))
+}
+
+// WithDefaultUint64 sets the default value of an uint64 flag (see cli.WithDefault).
+func WithDefaultUint64(v uint64) FlagOptionFunc {
+	return WithDefault(strconv.FormatUint(v, 10))
+}
+
+// WithDefaultFloat64 sets the default value of a float64 flag (see cli.WithDefault).
+func WithDefaultFloat64(v float64) FlagOptionFunc {
+	return WithDefault(strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+// WithDefaultString sets the default value of a string flag (see cli.WithDefault).
+func WithDefaultString(v string) FlagOptionFunc {
+	return WithDefault(v)
+}
+
+// WithDefaultDuration sets the default value of a time.Duration flag (see cli.WithDefault).
+func WithDefaultDuration(v time.Duration) FlagOptionFunc {
+	return WithDefault(v.String())
+}
+
+// WithEnv sets the environment variable with a value of the flag. The variable
+// is used if the flag was not set in the arguments, so the arguments override
+// the variable and the variable overrides the default value.
+//
+//   _ = cli.Int(register, "port", cli.WithEnv("PORT"))
+//
+// Environment variables may be disabled by the DefaultParser.DisableEnv.
+func WithEnv(name string) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.Env = name
+	}
+}
+
+// WithDeprecated marks the flag as deprecated. The flag still works but the
+// parser writes a warning into the DefaultParser.DeprecationOutput when it's
+// used:
+//
+//   flag --old is deprecated: use --new instead
+func WithDeprecated(msg string) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.Deprecated = msg
+	}
+}
+
+// WithHidden hides the flag from the help and completions. Hidden flags are
+// parsed as usual.
+func WithHidden() FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.Hidden = true
+	}
+}
+
+// WithPlaceholder sets the name of the flag value in the help instead of the
+// type of the value.
+//
+//   _ = cli.Int(register, "port", cli.WithPlaceholder("port")) // --port <port>
+func WithPlaceholder(s string) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.Placeholder = s
+	}
+}
+
+// WithSensitive hides the default value of the flag in the help (see
+// cli.SensitiveString).
+func WithSensitive() FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.Sensitive = true
+	}
+}
+
+// WithShortOnly makes the parser accept only the short form of the flag
+// (e.g. -v but not --verbose). The long name is still used for lookups
+// (e.g. DefaultParser.Lookup or constraints) but hidden from the help.
+//
+//   _ = cli.Bool(register, "verbose", cli.WithShort("v"), cli.WithShortOnly())
+func WithShortOnly() FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.ShortOnly = true
+		o.LongOnly = false
+	}
+}
+
+// WithLongOnly makes the parser accept only the long form of the flag (see
+// cli.WithShortOnly).
+func WithLongOnly() FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.LongOnly = true
+		o.ShortOnly = false
+	}
+}
+
+// WithPersistent makes the flag available in sub-parsers of the parser (see
+// DefaultParser.SubParser).
+func WithPersistent() FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.Persistent = true
+	}
+}
+
+// WithAnnotation adds the metadata to the flag, e.g. for help or completion
+// generators. Annotations don't affect the parsing.
+//
+//   _ = cli.String(register, "config", cli.WithAnnotation("completion", "files"))
+func WithAnnotation(key, value string) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.annotate(key, value)
+	}
+}
+
+// WithFlagGroup puts the flag into the group in the help.
+//
+//   output := &cli.FlagGroup{Name: "Output"}
+//
+//   _ = cli.Bool(register, "json", cli.WithFlagGroup(output))
+//   _ = cli.Bool(register, "yaml", cli.WithFlagGroup(output))
+func WithFlagGroup(g *FlagGroup) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.Group = g
+	}
+}
+
+// WithRef stores a reference to the registered flag into the ref. It's
+// useful to know if the flag was passed without comparing its value with the
+// default one.
+//
+//   var portRef cli.FlagRef
+//   port := cli.Int(register, "port", cli.WithDefault("8080"), cli.WithRef(&portRef))
+//
+//   // After the parse.
+//   if portRef.IsSet() {
+//       // ...
+//   }
+func WithRef(ref *FlagRef) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.refs = append(o.refs, ref)
+	}
+}
+
 // var _ FlagOptionApplyer = Global(false)
 //
 // type Global bool
@@ -257,6 +559,11 @@ type ArgOptions struct {
 	//     Usually when we use args in our CLIs they are required by default.
 	//     So yes, it's a little bit counfusing (why it isn't Optional?) but
 	//     it makes writing CLIs simpler with default options.
+	Hidden bool
+
+	Placeholder string
+
+	validators validators
 }
 
 func (o ArgOptions) ArgOptionApply(opts *ArgOptions) {
@@ -269,6 +576,29 @@ func (o ArgOptions) ArgOptionApply(opts *ArgOptions) {
 	}
 
 	opts.Necessary = o.Necessary
+
+	opts.Hidden = opts.Hidden || o.Hidden
+
+	if o.Placeholder != "" {
+		opts.Placeholder = o.Placeholder
+	}
+
+	opts.validators = append(opts.validators, o.validators...)
+}
+
+// WithArgPlaceholder sets the name of the arg value in the help instead of
+// the type of the value.
+func WithArgPlaceholder(s string) ArgOptionFunc {
+	return func(o *ArgOptions) {
+		o.Placeholder = s
+	}
+}
+
+// WithHiddenArg hides the arg from the help. Hidden args are parsed as usual.
+func WithHiddenArg() ArgOptionFunc {
+	return func(o *ArgOptions) {
+		o.Hidden = true
+	}
 }
 
 func (o *ArgOptions) applyName(name string) {
@@ -290,6 +620,8 @@ var _ RestOptionApplyer = RestOptions{}
 type RestOptions struct {
 	Name  string
 	Usage Usager
+	Min   int
+	Max   int
 }
 
 func (o RestOptions) RestOptionApply(opts *RestOptions) {
@@ -300,6 +632,14 @@ func (o RestOptions) RestOptionApply(opts *RestOptions) {
 	if o.Usage != nil {
 		opts.Usage = o.Usage
 	}
+
+	if o.Min != 0 {
+		opts.Min = o.Min
+	}
+
+	if o.Max != 0 {
+		opts.Max = o.Max
+	}
 }
 
 func (o *RestOptions) applyName(name string) {
@@ -313,3 +653,104 @@ func (o *RestOptions) applyRestOptions(options []RestOptionApplyer) {
 		}
 	}
 }
+
+// WithMinRest sets the minimum number of rest args. The parser returns
+// the ErrNotEnough if fewer values were passed.
+func WithMinRest(n int) RestOptionFunc {
+	return func(o *RestOptions) {
+		o.Min = n
+	}
+}
+
+// WithMaxRest sets the maximum number of rest args. The parser returns
+// the ErrTooMany if more values were passed.
+func WithMaxRest(n int) RestOptionFunc {
+	return func(o *RestOptions) {
+		o.Max = n
+	}
+}
+
+// Parser options.
+
+func applyParserOptions(p *DefaultParser, options []ParserOptionApplyer) {
+	for _, opt := range options {
+		if opt != nil {
+			opt.ParserOptionApply(p)
+		}
+	}
+}
+
+// StrictFlags makes the parser return an error for every flag which was not
+// registered. It's the default behavior, the option exists to make the intent
+// explicit and to revert IgnoreUnknownFlags.
+func StrictFlags() ParserOptionFunc {
+	return func(p *DefaultParser) {
+		p.IgnoreUnknownFlags = false
+	}
+}
+
+// StrictArgs makes the parser return an error for every positional argument
+// which does not match any registered arg or rest args. It's the default
+// behavior, the option exists to make the intent explicit and to revert
+// IgnoreUnknownArgs.
+func StrictArgs() ParserOptionFunc {
+	return func(p *DefaultParser) {
+		p.IgnoreUnknownArgs = false
+	}
+}
+
+// WithUniversal sets the DefaultParser.Universal mode.
+func WithUniversal() ParserOptionFunc {
+	return func(p *DefaultParser) {
+		p.Universal = true
+	}
+}
+
+// WithIgnoreUnknownFlags sets the DefaultParser.IgnoreUnknownFlags mode.
+func WithIgnoreUnknownFlags() ParserOptionFunc {
+	return func(p *DefaultParser) {
+		p.IgnoreUnknownFlags = true
+	}
+}
+
+// WithIgnoreUnknownArgs sets the DefaultParser.IgnoreUnknownArgs mode.
+func WithIgnoreUnknownArgs() ParserOptionFunc {
+	return func(p *DefaultParser) {
+		p.IgnoreUnknownArgs = true
+	}
+}
+
+// WithDisablePosixStyle sets the DefaultParser.DisablePosixStyle mode.
+func WithDisablePosixStyle() ParserOptionFunc {
+	return func(p *DefaultParser) {
+		p.DisablePosixStyle = true
+	}
+}
+
+// WithDisableInlineValue sets the DefaultParser.DisableInlineValue mode.
+func WithDisableInlineValue() ParserOptionFunc {
+	return func(p *DefaultParser) {
+		p.DisableInlineValue = true
+	}
+}
+
+// WithOverrideFlags sets the DefaultParser.OverrideFlags mode.
+func WithOverrideFlags() ParserOptionFunc {
+	return func(p *DefaultParser) {
+		p.OverrideFlags = true
+	}
+}
+
+// WithOverrideArgs sets the DefaultParser.OverrideArgs mode.
+func WithOverrideArgs() ParserOptionFunc {
+	return func(p *DefaultParser) {
+		p.OverrideArgs = true
+	}
+}
+
+// WithErrorHandling sets the DefaultParser.ErrorHandling.
+func WithErrorHandling(h ErrorHandling) ParserOptionFunc {
+	return func(p *DefaultParser) {
+		p.ErrorHandling = h
+	}
+}
@@ -3,7 +3,10 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -21,12 +24,29 @@ var (
 	ErrArgAfterRest = errors.New("arg after rest")
 
 	ErrUnknown = errors.New("unknown")
+
+	ErrNotEnough = errors.New("not enough values")
+
+	ErrTooMany = errors.New("too many values")
+
+	ErrHelp = errors.New("help requested")
+
+	ErrVersion = errors.New("version requested")
+
+	ErrNilTarget = errors.New("nil target pointer")
+
+	ErrSnapshotMismatch = errors.New("snapshot mismatch")
+
+	ErrConflict = errors.New("conflict")
+
+	ErrDependency = errors.New("missing dependency")
 )
 
 type ParseArgError struct {
-	Arg   string
-	Index int
-	Err   error
+	Arg      string
+	Index    int
+	Position int // Ordinal index of the positional arg in the arguments. Filled by the parser.
+	Err      error
 }
 
 func (e *ParseArgError) Error() string {
@@ -87,6 +107,24 @@ func (e *ParseFlagError) Is(err error) bool {
 	return ok && pe.Name == e.Name && errors.Is(pe.Err, e.Err)
 }
 
+// AmbiguousFlagError is returned in the PrefixMatch mode if a prefix matches
+// several long flags.
+type AmbiguousFlagError struct {
+	Name       string   // The flag from arguments with the prefix, e.g. --ver.
+	Candidates []string // Matched long flags, e.g. --verbose and --version.
+}
+
+func (e *AmbiguousFlagError) Error() string {
+	return fmt.Sprintf("cli: ambiguous flag '%s': matches %s", e.Name, strings.Join(e.Candidates, ", "))
+}
+
+func (e *AmbiguousFlagError) Unwrap() error { return ErrSyntax }
+
+func (e *AmbiguousFlagError) Is(err error) bool {
+	pe, ok := err.(*AmbiguousFlagError)
+	return ok && pe.Name == e.Name
+}
+
 type FlagError struct {
 	Short string
 	Long  string
@@ -114,15 +152,18 @@ func (e *FlagError) Error() string {
 	return fmt.Sprintf("cli: flag error: '%s': %s", name, msg)
 }
 
+func (e *FlagError) Unwrap() error { return e.Err }
+
 func (e *FlagError) Is(err error) bool {
 	pe, ok := err.(*FlagError)
 	return ok && pe.Short == e.Short && pe.Long == e.Long && errors.Is(pe.Err, e.Err)
 }
 
 type ArgError struct {
-	Name  string
-	Index int
-	Err   error
+	Name     string
+	Index    int
+	Position int // Ordinal index of the failing positional arg in the arguments. Filled by the parser.
+	Err      error
 }
 
 func (e *ArgError) Error() string {
@@ -138,6 +179,8 @@ func (e *ArgError) Error() string {
 	return fmt.Sprintf("cli: arg error: %s arg '%s': %s", nthNumber(e.Index), e.Name, msg)
 }
 
+func (e *ArgError) Unwrap() error { return e.Err }
+
 func (e *ArgError) Is(err error) bool {
 	pe, ok := err.(*ArgError)
 	return ok && pe.Name == e.Name && pe.Index == e.Index && errors.Is(pe.Err, e.Err)
@@ -161,11 +204,65 @@ func (e *RestArgsError) Error() string {
 	return fmt.Sprintf("cli: rest args error: '%s': %s", e.Name, msg)
 }
 
+func (e *RestArgsError) Unwrap() error { return e.Err }
+
 func (e *RestArgsError) Is(err error) bool {
 	pe, ok := err.(*RestArgsError)
 	return ok && pe.Name == e.Name && errors.Is(pe.Err, e.Err)
 }
 
+// Errors is a list of errors returned by the DefaultParser in the AllErrors
+// mode.
+type Errors []error
+
+// joinErrors returns nil for empty errs.
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return Errors(errs)
+}
+
+func (e Errors) Error() string {
+	var buf strings.Builder
+	for i, err := range e {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+
+		buf.WriteString(err.Error())
+	}
+
+	return buf.String()
+}
+
+// Is reports whether any of the errors matches the err.
+func (e Errors) Is(err error) bool {
+	for _, ee := range e {
+		if errors.Is(ee, err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error which matches the target.
+func (e Errors) As(target interface{}) bool {
+	for _, ee := range e {
+		if errors.As(ee, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Unwrap returns the errors. It's used by errors.Is and errors.As since Go
+// 1.20.
+func (e Errors) Unwrap() []error { return e }
+
 type Register interface {
 	RegisterFlag(flag Flag) error
 	RegisterArg(arg Arg) error
@@ -198,43 +295,104 @@ func (r *DefaultRegister) RegisterFlag(flag Flag) (err error) {
 		}
 	}()
 
+	if err := verifyFlag(&flag, true); err != nil {
+		return err
+	}
+
+	if _, _, ok := r.flags.Find(flag.Long, flag.Short); ok {
+		return &FlagError{
+			Long:  flag.Long,
+			Short: flag.Short,
+			Err:   ErrDuplicate,
+		}
+	}
+
+	r.flags.Add(flag)
+
+	return nil
+}
+
+// VerifyFlagOptions validates the options without registering a flag.
+// It returns the same errors as the DefaultRegister.RegisterFlag except
+// ErrDuplicate. A nil Value is allowed because the value is usually passed
+// to the cli.Var separately, but a Value pointing to nothing is reported
+// with the ErrNilTarget.
+//
+// NOTE: the conflict of the cli.Var name and the cli.WithLong is not checked
+// because the options don't have the name of the cli.Var.
+func VerifyFlagOptions(opts FlagOptions) error {
+	flag := newFlag(opts.Value, opts)
+	return verifyFlag(&flag, opts.Value != nil)
+}
+
+// verifyFlag returns errors of the flag shared by the RegisterFlag and the
+// VerifyFlagOptions. The value is checked only if checkValue is true.
+func verifyFlag(flag *Flag, checkValue bool) error {
+	// Errors of the options found by the cli.Var (e.g. a broken default).
+	if flag.err != nil {
+		return flag.err
+	}
+
+	if err := verifyFlagNames(flag.Short, flag.Long); err != nil {
+		return err
+	}
+
+	if checkValue && isNilValue(flag.Value) {
+		return &FlagError{
+			Short: flag.Short,
+			Long:  flag.Long,
+			Err:   ErrNilTarget,
+		}
+	}
+
+	// The flag must be accessible by the allowed form.
+	if (flag.ShortOnly && flag.Short == "") || (flag.LongOnly && flag.Long == "") {
+		return &FlagError{
+			Short: flag.Short,
+			Long:  flag.Long,
+			Err:   ErrMissingName,
+		}
+	}
+
+	if flag.ShortOnly && flag.LongOnly {
+		return &FlagError{
+			Short: flag.Short,
+			Long:  flag.Long,
+			Err:   ErrConflict,
+		}
+	}
+
+	return nil
+}
+
+func verifyFlagNames(short, long string) error {
 	// Check if short of long net is set.
-	if flag.Short == "" && flag.Long == "" {
+	if short == "" && long == "" {
 		return &FlagError{Err: ErrMissingName}
 	}
 
 	// Validate short flag.
-	if flag.Short != "" {
-		if !validShortFlag(flag.Short) {
+	if short != "" {
+		if !validShortFlag(short) {
 			return &FlagError{
-				Short: flag.Short,
-				Long:  flag.Long,
+				Short: short,
+				Long:  long,
 				Err:   ErrInvalidName,
 			}
 		}
 	}
 
 	// Validate long flag.
-	if flag.Long != "" {
-		if !validLongFlag(flag.Long) {
+	if long != "" {
+		if !validLongFlag(long) {
 			return &FlagError{
-				Short: flag.Short,
-				Long:  flag.Long,
+				Short: short,
+				Long:  long,
 				Err:   ErrInvalidName,
 			}
 		}
 	}
 
-	if _, _, ok := r.flags.Find(flag.Long, flag.Short); ok {
-		return &FlagError{
-			Long:  flag.Long,
-			Short: flag.Short,
-			Err:   ErrDuplicate,
-		}
-	}
-
-	r.flags.Add(flag)
-
 	return nil
 }
 
@@ -259,7 +417,17 @@ func validLongFlag(name string) bool {
 		return false
 	}
 
-	// We need to iterate by bytes not by runes.
+	// Trailing dashes are allowed (e.g. "help-"). The parser only cares about
+	// leading dashes and "=", so "--help-" is unambiguous.
+
+	// We need to iterate by bytes not by runes. All special characters are
+	// ASCII and bytes of multi-byte runes are never ASCII, so there is no need
+	// to decode UTF-8.
+	//
+	// The "second dash" rule: foundValid is false only for the second byte,
+	// so a dash there is rejected (e.g. "a-", "a-b"). Later dashes are allowed
+	// (e.g. "go-help", "help-"). Because the rule is byte based, a name which
+	// starts with a multi-byte rune (e.g. "ф-") passes it.
 	var foundValid bool
 	for i := 1; i < len(name); i++ {
 		c := name[i]
@@ -314,6 +482,13 @@ func (r *DefaultRegister) RegisterArg(arg Arg) (err error) {
 		}
 	}
 
+	if isNilValue(arg.Value) {
+		return &ArgError{
+			Name: arg.Name,
+			Err:  ErrNilTarget,
+		}
+	}
+
 	if _, _, ok := r.args.Get(arg.Name); ok {
 		return &ArgError{
 			Name: arg.Name,
@@ -372,6 +547,13 @@ func (r *DefaultRegister) RegisterRestArgs(rest RestArgs) (err error) {
 		}
 	}
 
+	if isNilValue(rest.Values) {
+		return &RestArgsError{
+			Name: rest.Name,
+			Err:  ErrNilTarget,
+		}
+	}
+
 	if !r.rest.IsZero() {
 		return &RestArgsError{
 			Name: rest.Name,
@@ -419,6 +601,33 @@ func (r *DefaultRegister) Flags() []Flag {
 	return r.flags.data
 }
 
+// VisibleFlags returns registered flags without hidden ones (see WithHidden).
+func (r *DefaultRegister) VisibleFlags() []Flag {
+	return visibleFlags(r.flags.data)
+}
+
+func visibleFlags(flags []Flag) []Flag {
+	visible := make([]Flag, 0, len(flags))
+	for i := range flags {
+		if !flags[i].Hidden {
+			visible = append(visible, flags[i])
+		}
+	}
+
+	return visible
+}
+
+func visibleArgs(args []Arg) []Arg {
+	visible := make([]Arg, 0, len(args))
+	for i := range args {
+		if !args[i].Hidden {
+			visible = append(visible, args[i])
+		}
+	}
+
+	return visible
+}
+
 func (r *DefaultRegister) Err() error {
 	if r.registerFlagErr != nil {
 		return r.registerFlagErr
@@ -435,6 +644,55 @@ func (r *DefaultRegister) Err() error {
 	return nil
 }
 
+// ReserveFlags pre-allocates space for n more flags. It is an optimization
+// hint for callers who know the number of flags ahead of time, so it should
+// be called before flags are registered.
+//
+//   var register cli.DefaultRegister
+//   register.ReserveFlags(2)
+//
+//   verbose := cli.Bool(&register, "verbose")
+//   port := cli.Int(&register, "port")
+func (r *DefaultRegister) ReserveFlags(n int) {
+	r.flags.Reserve(n)
+}
+
+// ReserveArgs pre-allocates space for n more args (see ReserveFlags).
+func (r *DefaultRegister) ReserveArgs(n int) {
+	r.args.Reserve(n)
+}
+
+// Reset removes all registered flags, args and rest args and clears
+// registration errors, so the register can be reused without reallocation.
+func (r *DefaultRegister) Reset() {
+	r.flags.Reset()
+	r.args.Reset()
+	r.rest = RestArgs{}
+	r.lastArgOptional = false
+	r.registerFlagErr = nil
+	r.registerArgErr = nil
+	r.registerRestArgsErr = nil
+}
+
+// Clone returns a copy of the register with all flags and args unset, as if
+// they were freshly registered. Flags and args registered in the clone do not
+// affect the original register.
+//
+// NOTE: values are not copied, so the clone sets the same variables.
+func (r *DefaultRegister) Clone() *DefaultRegister {
+	c := &DefaultRegister{
+		flags:               r.flags.Clone(),
+		args:                r.args.Clone(),
+		rest:                r.rest,
+		lastArgOptional:     r.lastArgOptional,
+		registerFlagErr:     r.registerFlagErr,
+		registerArgErr:      r.registerArgErr,
+		registerRestArgsErr: r.registerRestArgsErr,
+	}
+
+	return c
+}
+
 type Commander interface {
 	IsCommand(name string) bool
 	SetCommand(name string) (Register, error)
@@ -514,6 +772,22 @@ func (f *flags) Add(flag Flag) {
 	}
 }
 
+// Reserve grows the capacity of the flags to fit n more flags without
+// reallocation.
+func (f *flags) Reserve(n int) {
+	if n <= 0 || cap(f.data)-len(f.data) >= n {
+		return
+	}
+
+	data := make([]Flag, len(f.data), len(f.data)+n)
+	copy(data, f.data)
+	f.data = data
+
+	set := make([]bool, len(f.set), len(f.set)+n)
+	copy(set, f.set)
+	f.set = set
+}
+
 func (f *flags) Reset() {
 	f.data = f.data[:0]
 	f.set = f.set[:0]
@@ -521,6 +795,23 @@ func (f *flags) Reset() {
 	f.short = nil
 }
 
+func (f *flags) Clone() flags {
+	var c flags
+	for _, flag := range f.data {
+		flag.set = false
+		if flag.Annotations != nil {
+			annotations := make(map[string]string, len(flag.Annotations))
+			for k, v := range flag.Annotations {
+				annotations[k] = v
+			}
+			flag.Annotations = annotations
+		}
+		c.Add(flag)
+	}
+
+	return c
+}
+
 type args struct {
 	data  []Arg
 	set   []bool         // Markers if args were set.
@@ -558,12 +849,38 @@ func (a *args) Add(arg Arg) {
 	a.index[arg.Name] = idx
 }
 
+// Reserve grows the capacity of the args to fit n more args without
+// reallocation.
+func (a *args) Reserve(n int) {
+	if n <= 0 || cap(a.data)-len(a.data) >= n {
+		return
+	}
+
+	data := make([]Arg, len(a.data), len(a.data)+n)
+	copy(data, a.data)
+	a.data = data
+
+	set := make([]bool, len(a.set), len(a.set)+n)
+	copy(set, a.set)
+	a.set = set
+}
+
 func (a *args) Reset() {
 	a.data = a.data[:0]
 	a.set = a.set[:0]
 	a.index = nil
 }
 
+func (a *args) Clone() args {
+	var c args
+	for _, arg := range a.data {
+		arg.set = false
+		c.Add(arg)
+	}
+
+	return c
+}
+
 type Parser interface {
 	Parse(commander Commander, r Register, arguments []string) error
 	FormatLongFlag(name string) string
@@ -572,102 +889,609 @@ type Parser interface {
 
 var _ Parser = (*DefaultParser)(nil)
 
+// ErrorHandling defines how the DefaultParser behaves if the parse fails.
+type ErrorHandling int
+
+const (
+	// ContinueOnError makes the Parse return the error.
+	ContinueOnError ErrorHandling = iota
+	// ExitOnError makes the Parse print the error to the stderr and exit with
+	// status 2, or 0 for the ErrHelp and ErrVersion.
+	ExitOnError
+	// PanicOnError makes the Parse panic with the error.
+	PanicOnError
+)
+
+var osExit = os.Exit // For tests.
+
 type DefaultParser struct {
+	Name               string // Program name for the help. Base of os.Args[0] if empty.
 	Universal          bool
 	IgnoreUnknownFlags bool
 	IgnoreUnknownArgs  bool
 	DisablePosixStyle  bool
 	DisableInlineValue bool
+	DisableEnv         bool      // Do not read flags from environment variables.
+	EnableNegation     bool      // Accept --no-<name> for bool flags.
+	Commander          Commander // Used if Parse was called without a commander.
+	DeprecationOutput  io.Writer // Output for deprecation warnings. Output() if nil.
+
+	// AllErrors makes the Parse collect all errors of values and missing
+	// required flags and args instead of returning the first one. Collected
+	// errors are returned as Errors. Syntax errors and unknown flags or args
+	// still stop the parsing.
+	AllErrors bool
+
+	// StopOnFirstNonFlag makes the first positional arg terminate the flags
+	// like "--" does, but the arg itself is parsed as a positional arg.
+	// Numbers (e.g. -1) are positional args too.
+	StopOnFirstNonFlag bool
+
+	// Passthrough makes the Parse put all args after "--" into the
+	// PassthroughArgs instead of positional args.
+	Passthrough bool
+
+	// PrefixMatch allows to pass an unambiguous prefix of a long flag name
+	// (e.g. --verb for --verbose). If the prefix matches several flags the
+	// Parse returns the AmbiguousFlagError. Hidden flags are not matched by
+	// a prefix.
+	PrefixMatch bool
+
+	// NormalizeFunc transforms flag names from arguments before the lookup,
+	// e.g. to accept --log_level for the --log-level flag. The negation and
+	// PrefixMatch work with normalized names.
+	NormalizeFunc func(name string) string
+
+	// CaseInsensitive makes lookup of long flags case-insensitive (e.g.
+	// --Verbose for the --verbose flag). Short flags are always
+	// case-sensitive. Long flags which differ only by case (e.g. --Foo and
+	// --foo) are reported as ErrDuplicate by the Parse.
+	CaseInsensitive bool
+
+	// OverrideFlags makes the ParseFromMap set flags which were already set.
+	OverrideFlags bool
+
+	// OverrideArgs makes the ParseFromMap set positional args which were
+	// already set.
+	OverrideArgs bool
+
+	// HelpFunc is called if the --help/-h flag was passed, then the Parse
+	// returns the ErrHelp. The flag is registered only if HelpFunc is set,
+	// see DefaultHelpFunc for the default implementation.
+	HelpFunc func(p *DefaultParser)
+
+	// VersionFunc is called if the --version flag was passed, then the Parse
+	// returns the ErrVersion. The flag is registered only if VersionFunc is
+	// set.
+	VersionFunc func(p *DefaultParser)
+
+	// ErrorHandling defines the behavior of the Parse on errors. The Parse
+	// returns errors by default (see ContinueOnError).
+	ErrorHandling ErrorHandling
+
+	output      io.Writer     // Output for warnings, errors and the help (see SetOutput).
+	sources     []valueSource // Sources of values for unset flags in priority order.
+	middlewares []Middleware  // Middlewares for every value set.
+	sourcesErr  error         // Sources first error.
+	constraints []constraint  // Constraints of flags checked after the parse.
+	inherited   []Flag        // Persistent flags of parent parsers (see SubParser).
+	flagSets    []*FlagSet    // Sets added into every register (see AddFlagSet).
+
+	register    Register // Register of the last parse.
+	unknown     []string // Ignored unknown flags of the last parse.
+	rest        []string // Positional args without named args of the last parse.
+	passthrough []string // Args after "--" of the last parse (see Passthrough).
+	commands    []string // Path of the commander of the last parse.
+}
 
-	// TODO(SuperPaitnamn): allow access to the unknown flags.
-	// unknown []string // Unknown flags (without named flags).
+// NewDefaultParser returns a new DefaultParser with the options applied in
+// order. A zero DefaultParser is ready to use as well.
+//
+//   parser := cli.NewDefaultParser(cli.WithUniversal(), cli.WithIgnoreUnknownArgs())
+func NewDefaultParser(options ...ParserOptionApplyer) *DefaultParser {
+	var p DefaultParser
+	applyParserOptions(&p, options)
+	return &p
 }
 
-func (p *DefaultParser) Parse(commander Commander, r Register, arguments []string) error {
-	// If user has ignored errors return them here.
-	if err := r.Err(); err != nil {
+func (p *DefaultParser) useRegister(r Register) error {
+	p.register = r
+
+	return p.prepareRegister(r)
+}
+
+// prepareRegister registers flags of the parser (e.g. --help or inherited
+// ones) in the register and saves default values of flags and args. It may
+// be called several times for the same register.
+func (p *DefaultParser) prepareRegister(r Register) error {
+	if err := p.registerInherited(r); err != nil {
 		return err
 	}
 
-	var (
-		argMode          bool
-		argIdx           int
-		flagsTerminated  bool
-		foundCommandFlag bool
-	)
-	for {
-		if len(arguments) == 0 {
-			break
+	if err := p.registerFlagSets(r); err != nil {
+		return err
+	}
+
+	if p.HelpFunc != nil {
+		if err := p.registerHelp(r); err != nil {
+			return err
 		}
+	}
 
-		arg := arguments[0]
-		arguments = arguments[1:]
+	if p.VersionFunc != nil {
+		if err := p.registerVersion(r); err != nil {
+			return err
+		}
+	}
 
-		// Commands or Args.
-		if len(arg) == 0 || flagsTerminated || arg[0] != '-' || arg == "-" || isNumber(arg) || isDuration(arg) {
-			// Check if the arg is a command.
-			if !argMode && commander != nil && commander.IsCommand(arg) {
-				register, err := commander.SetCommand(arg)
-				if err != nil {
-					return err
-				}
+	if p.CaseInsensitive {
+		if err := verifyFoldedLongs(r.Flags()); err != nil {
+			return err
+		}
+	}
 
-				r = register
-				continue
-			}
+	// Save default values for the help (see FormatHelp).
+	flags := r.Flags()
+	for i := range flags {
+		if !flags[i].defaultSaved {
+			flags[i].SaveDefault()
+		}
+	}
 
-			// Parse rest as args.
-			argMode = true
+	args := r.Args()
+	for i := range args {
+		if !args[i].defaultSaved {
+			args[i].SaveDefault()
+		}
+	}
 
-			a, ok := r.Arg(argIdx)
-			if ok {
-				if err := a.Value.Set(arg); err != nil {
-					return &ArgError{
-						Name:  a.Name,
-						Index: argIdx,
-						Err:   err,
-					}
-				}
+	return nil
+}
 
-				a.MarkSet()
-			} else {
-				rest := r.Rest()
-				if rest == nil {
-					if p.IgnoreUnknownArgs {
-						argIdx++
-						continue
-					}
+// Reset clears the state of the last Parse (see Unknown and RestArgs).
+// Modes (e.g. Universal or IgnoreUnknownFlags) are configuration and are not
+// changed.
+func (p *DefaultParser) Reset() {
+	p.register = nil
+	p.unknown = p.unknown[:0]
+	p.rest = p.rest[:0]
+	p.passthrough = p.passthrough[:0]
+	p.commands = nil
+}
 
-					return &ParseArgError{
-						Arg:   arg,
-						Index: argIdx,
-						Err:   ErrUnknown,
-					}
-				}
+// Clone returns a copy of the parser with the same modes, sources,
+// middlewares and constraints but without the state of the last parse.
+// Sources and constraints added to the clone do not affect the original
+// parser.
+//
+// Flags and args are owned by the Register, see DefaultRegister.Clone.
+func (p *DefaultParser) Clone() *DefaultParser {
+	c := *p
+
+	c.sources = append([]valueSource(nil), p.sources...)
+	c.middlewares = append([]Middleware(nil), p.middlewares...)
+	c.constraints = make([]constraint, len(p.constraints))
+	for i, cs := range p.constraints {
+		c.constraints[i] = constraint{
+			kind:  cs.kind,
+			names: append([]string(nil), cs.names...),
+		}
+	}
+	if len(p.constraints) == 0 {
+		c.constraints = nil
+	}
 
-				if err := rest.Add(arg); err != nil {
-					return &ArgError{
-						Name:  rest.Name,
-						Index: argIdx,
-						Err:   err,
-					}
-				}
-			}
+	c.inherited = append([]Flag(nil), p.inherited...)
+	c.flagSets = append([]*FlagSet(nil), p.flagSets...)
 
-			argIdx++
+	c.register = nil
+	c.unknown = nil
+	c.rest = nil
+	c.passthrough = nil
+	c.commands = nil
+
+	return &c
+}
+
+// SubParser returns a clone of the parser (see Clone) which registers all
+// persistent flags of the last parse (see WithPersistent) in its registers.
+// It allows to have root flags like --verbose in every subcommand.
+//
+// Inherited flags are unset in the sub-parser and share values with the
+// parent, so the sub-parser sets the same variables. Flags with names already
+// taken in the register of the sub-parser are not inherited.
+func (p *DefaultParser) SubParser() *DefaultParser {
+	c := p.Clone()
+
+	if p.register == nil {
+		return c
+	}
+
+	// Inherited flags of the parser are in its register already.
+	c.inherited = c.inherited[:0]
 
+	flags := p.register.Flags()
+	for i := range flags {
+		flag := flags[i]
+		if !flag.Persistent || flag.commandFlag {
 			continue
 		}
 
-		// Flags.
-		numMinuses := 1
-		if arg[1] == '-' {
-			numMinuses++
+		flag.set = false
+		c.inherited = append(c.inherited, flag)
+	}
 
-			// "--" terminates the flags.
-			if len(arg) == 2 {
-				flagsTerminated = true
-				continue
+	return c
+}
+
+func (p *DefaultParser) registerInherited(r Register) error {
+	for _, flag := range p.inherited {
+		if flag.Long != "" {
+			if _, ok := r.LongFlag(flag.Long); ok {
+				continue
+			}
+		}
+
+		if flag.Short != "" {
+			if _, ok := r.ShortFlag(flag.Short); ok {
+				continue
+			}
+		}
+
+		if err := r.RegisterFlag(flag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Unknown returns a copy of unknown flags ignored by the last Parse (see
+// IgnoreUnknownFlags) in the "--first-unknown" form. Unknown flags are
+// arguments which look like flags but were not registered.
+func (p *DefaultParser) Unknown() []string {
+	return append([]string(nil), p.unknown...)
+}
+
+// RestArgs returns a copy of positional args of the last Parse which were not
+// consumed by named args: values of the Register.Rest or ignored unknown args
+// (see IgnoreUnknownArgs). Unlike unknown flags they never start with a dash
+// unless flags were terminated by "--".
+func (p *DefaultParser) RestArgs() []string {
+	return append([]string(nil), p.rest...)
+}
+
+// PassthroughArgs returns a copy of args after the "--" terminator of the last
+// Parse if the Passthrough mode is enabled. They are not parsed at all.
+func (p *DefaultParser) PassthroughArgs() []string {
+	return append([]string(nil), p.passthrough...)
+}
+
+// SetOutput sets the output for deprecation warnings, errors of the
+// ExitOnError mode and the help of the DefaultHelpFunc. Nil resets the output
+// to the default.
+func (p *DefaultParser) SetOutput(w io.Writer) {
+	p.output = w
+}
+
+// Output returns the output of the parser (see SetOutput). It's os.Stderr by
+// default.
+func (p *DefaultParser) Output() io.Writer {
+	if p.output == nil {
+		return os.Stderr
+	}
+
+	return p.output
+}
+
+// ParsedCommands returns a copy of the command path of the last Parse. The
+// path is taken from the Path() []string method of the commander (e.g.
+// StaticCommander or TreeCommander). It's nil if the commander is nil or has
+// no such method.
+func (p *DefaultParser) ParsedCommands() []string {
+	if p.commands == nil {
+		return nil
+	}
+
+	return append([]string{}, p.commands...)
+}
+
+// Lookup returns the flag of the last parsed register by its long or short
+// name, or nil if there is no such flag.
+//
+// The returned flag is live: it reflects values set by the Parse. Mutating it
+// between the RegisterFlag and the Parse is undefined behaviour.
+func (p *DefaultParser) Lookup(name string) *Flag {
+	if p.register == nil {
+		return nil
+	}
+
+	if flag, ok := p.register.LongFlag(name); ok {
+		return flag
+	}
+
+	if flag, ok := p.register.ShortFlag(name); ok {
+		return flag
+	}
+
+	return nil
+}
+
+// LookupArg returns the positional arg of the last parsed register by its
+// name, or nil if there is no such arg. The same restrictions as for the
+// Lookup apply.
+func (p *DefaultParser) LookupArg(name string) *Arg {
+	if p.register == nil {
+		return nil
+	}
+
+	args := p.register.Args()
+	for i := range args {
+		if args[i].Name == name {
+			return &args[i]
+		}
+	}
+
+	return nil
+}
+
+// Visit calls the fn for each flag of the last parsed register which was set
+// by the Parse, in the registration order.
+func (p *DefaultParser) Visit(fn func(flag *Flag, set bool)) {
+	p.VisitAll(func(flag *Flag, set bool) {
+		if set {
+			fn(flag, set)
+		}
+	})
+}
+
+// VisitAll calls the fn for each flag of the last parsed register, in the
+// registration order. The set reports if the flag was set by the Parse.
+func (p *DefaultParser) VisitAll(fn func(flag *Flag, set bool)) {
+	if p.register == nil {
+		return
+	}
+
+	flags := p.register.Flags()
+	for i := range flags {
+		fn(&flags[i], flags[i].Set())
+	}
+}
+
+// VisitArgs calls the fn for each positional arg of the last parsed register
+// which was set by the Parse, in the registration order.
+func (p *DefaultParser) VisitArgs(fn func(arg *Arg, set bool)) {
+	p.VisitAllArgs(func(arg *Arg, set bool) {
+		if set {
+			fn(arg, set)
+		}
+	})
+}
+
+// VisitAllArgs calls the fn for each positional arg of the last parsed
+// register, in the registration order. The set reports if the arg was set by
+// the Parse.
+func (p *DefaultParser) VisitAllArgs(fn func(arg *Arg, set bool)) {
+	if p.register == nil {
+		return
+	}
+
+	args := p.register.Args()
+	for i := range args {
+		fn(&args[i], args[i].Set())
+	}
+}
+
+// FlagValues returns values of flags of the last parsed register by long
+// names, or short names if long ones are empty. Flags added by the parser
+// (e.g. --help) are skipped. Unset flags have default values.
+func (p *DefaultParser) FlagValues() map[string]string {
+	values := make(map[string]string)
+	if p.register == nil {
+		return values
+	}
+
+	flags := p.register.Flags()
+	for i := range flags {
+		flag := &flags[i]
+		if flag.commandFlag {
+			continue
+		}
+
+		values[flag.name()] = flag.Value.String()
+	}
+
+	return values
+}
+
+// ArgValues returns values of positional args of the last parsed register in
+// the registration order. Unset args have default values.
+func (p *DefaultParser) ArgValues() []string {
+	if p.register == nil {
+		return nil
+	}
+
+	args := p.register.Args()
+	values := make([]string, len(args))
+	for i := range args {
+		values[i] = args[i].Value.String()
+	}
+
+	return values
+}
+
+func (p *DefaultParser) Parse(commander Commander, r Register, arguments []string) error {
+	err := p.parse(commander, r, arguments)
+	if err == nil {
+		return nil
+	}
+
+	switch p.ErrorHandling {
+	case ExitOnError:
+		code := 2
+		if errors.Is(err, ErrHelp) || errors.Is(err, ErrVersion) {
+			code = 0
+		} else {
+			fmt.Fprintln(p.Output(), err)
+		}
+
+		osExit(code)
+
+	case PanicOnError:
+		panic(err)
+	}
+
+	return err
+}
+
+func (p *DefaultParser) parse(commander Commander, r Register, arguments []string) error {
+	if p.sourcesErr != nil {
+		return p.sourcesErr
+	}
+
+	// If user has ignored errors return them here.
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	if commander == nil {
+		commander = p.Commander
+	}
+
+	p.Reset()
+
+	// Commanders keep the path of the last parse (e.g. StaticCommander).
+	if resetter, ok := commander.(interface{ Reset() }); ok {
+		resetter.Reset()
+	}
+
+	if pather, ok := commander.(interface{ Path() []string }); ok {
+		defer func() {
+			p.commands = append([]string{}, pather.Path()...)
+		}()
+	}
+
+	if err := p.useRegister(r); err != nil {
+		return err
+	}
+
+	// Registers of the root and the commands (see checkConstraints).
+	registers := []Register{r}
+
+	var (
+		argMode          bool
+		argIdx           int
+		restCount        int
+		flagsTerminated  bool
+		foundCommandFlag bool
+		errs             []error // Collected errors in the AllErrors mode.
+	)
+	for {
+		if len(arguments) == 0 {
+			break
+		}
+
+		arg := arguments[0]
+		arguments = arguments[1:]
+
+		// Commands or Args.
+		if len(arg) == 0 || flagsTerminated || arg[0] != '-' || arg == "-" || isNumber(arg) || isDuration(arg) {
+			// Check if the arg is a command.
+			if !argMode && commander != nil && commander.IsCommand(arg) {
+				register, err := commander.SetCommand(arg)
+				if err != nil {
+					return err
+				}
+
+				r = register
+				if err := p.useRegister(r); err != nil {
+					return err
+				}
+
+				registers = append(registers, r)
+
+				continue
+			}
+
+			// Parse rest as args.
+			argMode = true
+
+			// The first positional arg terminates the flags.
+			if p.StopOnFirstNonFlag {
+				flagsTerminated = true
+			}
+
+			a, ok := r.Arg(argIdx)
+			if ok {
+				if err := p.set(a.Name, arg, a.Value); err != nil {
+					err = &ArgError{
+						Name:     a.Name,
+						Index:    argIdx,
+						Position: argIdx,
+						Err:      err,
+					}
+					if !p.AllErrors {
+						return err
+					}
+
+					errs = append(errs, err)
+				}
+
+				a.MarkSet()
+			} else {
+				rest := r.Rest()
+				if rest == nil {
+					if p.IgnoreUnknownArgs {
+						p.rest = append(p.rest, arg)
+						argIdx++
+						continue
+					}
+
+					return &ParseArgError{
+						Arg:      arg,
+						Index:    argIdx,
+						Position: argIdx,
+						Err:      ErrUnknown,
+					}
+				}
+
+				if err := p.set(rest.Name, arg, (*restSetter)(rest)); err != nil {
+					err = &ArgError{
+						Name:     rest.Name,
+						Index:    argIdx,
+						Position: argIdx,
+						Err:      err,
+					}
+					if !p.AllErrors {
+						return err
+					}
+
+					errs = append(errs, err)
+				}
+
+				p.rest = append(p.rest, arg)
+				restCount++
+			}
+
+			argIdx++
+
+			continue
+		}
+
+		// Flags.
+		numMinuses := 1
+		if arg[1] == '-' {
+			numMinuses++
+
+			// "--" terminates the flags.
+			if len(arg) == 2 {
+				if p.Passthrough {
+					p.passthrough = append(p.passthrough, arguments...)
+					break
+				}
+
+				flagsTerminated = true
+				continue
 			}
 		}
 
@@ -705,6 +1529,7 @@ func (p *DefaultParser) Parse(commander Commander, r Register, arguments []strin
 				flag          *Flag
 				knownflag     bool
 				lastShortFlag bool
+				negated       bool
 			)
 			if shortFlag {
 				originalName := name
@@ -720,7 +1545,7 @@ func (p *DefaultParser) Parse(commander Commander, r Register, arguments []strin
 					value = ""
 				}
 
-				flag, knownflag = r.ShortFlag(name)
+				flag, knownflag = shortForm(r.ShortFlag(p.normalize(name)))
 
 				if knownflag {
 					// Parse Short-flag+parameter combining (-a parm -> -aparm).
@@ -744,17 +1569,28 @@ func (p *DefaultParser) Parse(commander Commander, r Register, arguments []strin
 			} else {
 				restName = ""
 
-				flag, knownflag = r.LongFlag(name)
+				lookupName := p.normalize(name)
+
+				flag, knownflag = longForm(p.longFlag(r, lookupName))
 				if !knownflag && p.Universal {
-					flag, knownflag = r.ShortFlag(name)
+					flag, knownflag = shortForm(r.ShortFlag(lookupName))
 				}
-			}
 
-			if !knownflag {
-				if p.IgnoreUnknownFlags {
-					continue
+				if !knownflag && p.EnableNegation {
+					flag, knownflag = longForm(p.negatedFlag(r, lookupName))
+					negated = knownflag
+				}
+
+				if !knownflag && p.PrefixMatch {
+					var err error
+					flag, knownflag, err = p.prefixFlag(r, lookupName)
+					if err != nil {
+						return err
+					}
 				}
+			}
 
+			if !knownflag {
 				var fullName string
 				if shortFlag {
 					fullName = p.FormatShortFlag(name)
@@ -762,12 +1598,30 @@ func (p *DefaultParser) Parse(commander Commander, r Register, arguments []strin
 					fullName = p.FormatLongFlag(name)
 				}
 
+				if p.IgnoreUnknownFlags {
+					p.unknown = append(p.unknown, fullName)
+					continue
+				}
+
 				return &ParseFlagError{
 					Name: fullName,
 					Err:  ErrUnknown,
 				}
 			}
 
+			if negated {
+				// --no-<name> is always false and doesn't take a value.
+				if hasValue {
+					return &ParseFlagError{
+						Name: p.FormatLongFlag(name),
+						Err:  ErrSyntax,
+					}
+				}
+
+				hasValue = true
+				value = "false"
+			}
+
 			if (!shortFlag || lastShortFlag) && !hasValue && len(arguments) > 0 {
 				next := arguments[0]
 
@@ -803,12 +1657,22 @@ func (p *DefaultParser) Parse(commander Commander, r Register, arguments []strin
 				}
 			}
 
-			if err := flag.Value.Set(value); err != nil {
-				return &FlagError{
+			// Warn only once per parse.
+			if flag.Deprecated != "" && !flag.Set() {
+				p.warnDeprecated(flag)
+			}
+
+			if err := p.setFlag(flag, value); err != nil {
+				err = &FlagError{
 					Short: flag.Short,
 					Long:  flag.Long,
 					Err:   err,
 				}
+				if !p.AllErrors {
+					return err
+				}
+
+				errs = append(errs, err)
 			}
 
 			if flag.commandFlag {
@@ -822,21 +1686,55 @@ func (p *DefaultParser) Parse(commander Commander, r Register, arguments []strin
 
 	// Don't chec required flags and args if we in "command flag" mode.
 	if foundCommandFlag {
-		return nil
+		if p.HelpFunc != nil && helpRequested(r) {
+			p.HelpFunc(p)
+			return ErrHelp
+		}
+
+		if p.VersionFunc != nil && versionRequested(r) {
+			p.VersionFunc(p)
+			return ErrVersion
+		}
+
+		return joinErrors(errs)
 	}
 
-	// Check required flags.
+	// Fill unset flags from other sources.
 	flags := r.Flags()
+	if err := p.applySources(flags); err != nil {
+		if !p.AllErrors {
+			return err
+		}
+
+		errs = append(errs, err.(Errors)...)
+	}
+
+	// Check required flags.
 	for i := range flags {
 		flag := &flags[i]
 
 		if !flag.Set() && flag.Required() {
-			return &FlagError{
+			err := &FlagError{
 				Short: flag.Short,
 				Long:  flag.Long,
 				Err:   ErrNotProvided,
 			}
+			if !p.AllErrors {
+				return err
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	// Check constraints of flags.
+	if err := p.checkConstraints(registers); err != nil {
+		ce, ok := err.(Errors)
+		if !ok {
+			return err
 		}
+
+		errs = append(errs, ce...)
 	}
 
 	// Check required args.
@@ -845,16 +1743,290 @@ func (p *DefaultParser) Parse(commander Commander, r Register, arguments []strin
 		arg := &args[i]
 
 		if !arg.Set() && arg.Required() {
-			return &ArgError{
+			err := &ArgError{
 				Name: arg.Name,
 				Err:  ErrNotProvided,
 			}
+			if !p.AllErrors {
+				return err
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	// Check rest args bounds.
+	if rest := r.Rest(); rest != nil {
+		var err error
+		if restCount < rest.Min {
+			err = &RestArgsError{
+				Name: rest.Name,
+				Err:  ErrNotEnough,
+			}
+		} else if rest.Max > 0 && restCount > rest.Max {
+			err = &RestArgsError{
+				Name: rest.Name,
+				Err:  ErrTooMany,
+			}
+		}
+
+		if err != nil {
+			if !p.AllErrors {
+				return err
+			}
+
+			errs = append(errs, err)
 		}
 	}
 
+	return joinErrors(errs)
+}
+
+// applySources returns Errors in the AllErrors mode.
+func (p *DefaultParser) applySources(flags []Flag) error {
+	var errs []error
+	for i := range flags {
+		flag := &flags[i]
+
+		// Command flags can be set only by arguments.
+		if flag.Set() || flag.commandFlag {
+			continue
+		}
+
+		// Variables from the cli.WithEnv have the highest priority.
+		if !p.DisableEnv && flag.Env != "" {
+			ok, err := p.applySource(flagEnvSource{}, flag)
+			if err != nil {
+				if !p.AllErrors {
+					return err
+				}
+
+				errs = append(errs, err)
+				flag.MarkSet() // Don't report it as not provided.
+				continue
+			}
+
+			if ok {
+				continue
+			}
+		}
+
+		for _, source := range p.sources {
+			if _, ok := source.(envValueSource); ok && p.DisableEnv {
+				continue
+			}
+
+			ok, err := p.applySource(source, flag)
+			if err != nil {
+				if !p.AllErrors {
+					return err
+				}
+
+				errs = append(errs, err)
+				flag.MarkSet() // Don't report it as not provided.
+				break
+			}
+
+			if ok {
+				break
+			}
+		}
+	}
+
+	return joinErrors(errs)
+}
+
+// setFlag sets the value of the flag and fills the ParseValueError.Flag with
+// the flag name.
+func (p *DefaultParser) setFlag(flag *Flag, value string) error {
+	err := p.set(flag.name(), value, flag.Value)
+
+	var pe *ParseValueError
+	if errors.As(err, &pe) && pe.Flag == "" {
+		pe.Flag = flag.name()
+	}
+
+	return err
+}
+
+func (p *DefaultParser) applySource(source valueSource, flag *Flag) (ok bool, err error) {
+	values, ok := source.lookup(flag)
+	if !ok {
+		return false, nil
+	}
+
+	for _, value := range values {
+		if err := p.setFlag(flag, value); err != nil {
+			if es, ok := source.(envValueSource); ok {
+				err = &EnvError{Name: es.variable(flag), Err: err}
+			}
+
+			return false, &FlagError{
+				Short: flag.Short,
+				Long:  flag.Long,
+				Err:   err,
+			}
+		}
+	}
+
+	flag.MarkSet()
+
+	return true, nil
+}
+
+func (p *DefaultParser) normalize(name string) string {
+	if p.NormalizeFunc == nil {
+		return name
+	}
+
+	return p.NormalizeFunc(name)
+}
+
+// verifyFoldedLongs returns ErrDuplicate for long flags which differ only by
+// case, they are ambiguous in the CaseInsensitive mode.
+func verifyFoldedLongs(flags []Flag) error {
+	seen := make(map[string]struct{}, len(flags))
+	for i := range flags {
+		if flags[i].Long == "" {
+			continue
+		}
+
+		name := strings.ToLower(flags[i].Long)
+		if _, ok := seen[name]; ok {
+			return &FlagError{
+				Long:  flags[i].Long,
+				Short: flags[i].Short,
+				Err:   ErrDuplicate,
+			}
+		}
+
+		seen[name] = struct{}{}
+	}
+
 	return nil
 }
 
+// longFlag finds a flag by the long name. It respects the CaseInsensitive
+// mode.
+func (p *DefaultParser) longFlag(r Register, name string) (*Flag, bool) {
+	flag, ok := r.LongFlag(name)
+	if ok || !p.CaseInsensitive {
+		return flag, ok
+	}
+
+	flags := r.Flags()
+	for i := range flags {
+		if flags[i].Long != "" && strings.ToLower(flags[i].Long) == strings.ToLower(name) {
+			return &flags[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// prefixFlag finds a flag with the long name which starts with the prefix.
+func (p *DefaultParser) prefixFlag(r Register, name string) (*Flag, bool, error) {
+	prefix := name
+	if p.CaseInsensitive {
+		prefix = strings.ToLower(prefix)
+	}
+
+	var (
+		found      *Flag
+		candidates []string
+	)
+	flags := r.Flags()
+	for i := range flags {
+		long := flags[i].Long
+		if p.CaseInsensitive {
+			long = strings.ToLower(long)
+		}
+
+		// Hidden flags are matched only by the full name.
+		if long != "" && !flags[i].ShortOnly && !flags[i].Hidden && strings.HasPrefix(long, prefix) {
+			found = &flags[i]
+			candidates = append(candidates, p.FormatLongFlag(flags[i].Long))
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, false, nil
+
+	case 1:
+		return found, true, nil
+
+	default:
+		return nil, false, &AmbiguousFlagError{
+			Name:       p.FormatLongFlag(name),
+			Candidates: candidates,
+		}
+	}
+}
+
+// shortForm drops the flag found by the short name if only the long form of
+// the flag is allowed (see WithLongOnly).
+func shortForm(flag *Flag, ok bool) (*Flag, bool) {
+	if !ok || flag.LongOnly {
+		return nil, false
+	}
+
+	return flag, true
+}
+
+// longForm drops the flag found by the long name if only the short form of
+// the flag is allowed (see WithShortOnly).
+func longForm(flag *Flag, ok bool) (*Flag, bool) {
+	if !ok || flag.ShortOnly {
+		return nil, false
+	}
+
+	return flag, true
+}
+
+// negatedFlag finds a bool flag for the "no-<name>" flag name. Flags which
+// names already start with "no-" cannot be negated.
+func (p *DefaultParser) negatedFlag(r Register, name string) (*Flag, bool) {
+	if p.CaseInsensitive {
+		name = strings.ToLower(name)
+	}
+
+	const prefix = "no-"
+	if len(name) <= len(prefix) || name[:len(prefix)] != prefix {
+		return nil, false
+	}
+
+	name = name[len(prefix):]
+	if len(name) >= len(prefix) && name[:len(prefix)] == prefix {
+		return nil, false
+	}
+
+	flag, ok := p.longFlag(r, name)
+	if !ok {
+		return nil, false
+	}
+
+	if fv, ok := flag.Value.(boolFlag); !ok || !fv.IsBoolFlag() {
+		return nil, false
+	}
+
+	return flag, true
+}
+
+func (p *DefaultParser) warnDeprecated(flag *Flag) {
+	w := p.DeprecationOutput
+	if w == nil {
+		w = p.Output()
+	}
+
+	name := p.FormatLongFlag(flag.Long)
+	if name == "" {
+		name = p.FormatShortFlag(flag.Short)
+	}
+
+	_, _ = fmt.Fprintf(w, "flag %s is deprecated: %s\n", name, flag.Deprecated)
+}
+
 func (p *DefaultParser) FormatLongFlag(name string) string {
 	if name == "" {
 		return ""
@@ -1,11 +1,15 @@
 package cli
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math"
+	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 	"unsafe"
@@ -75,6 +79,95 @@ var (
 		{strconv.FormatUint(uint64(minUint), 10), uint(minUint)},
 	}
 
+	commonInt64Values = []commonValue{
+		{"0", int64(0)},
+		{"-0", int64(0)},
+		{"1337", int64(1337)},
+		{"-7331", int64(-7331)},
+		{"0xABC", int64(0xABC)},
+		{"-0xCBA", int64(-0xCBA)},
+		{"0b10111011", int64(0b10111011)},
+		{"-0b11011101", int64(-0b11011101)},
+		{strconv.FormatInt(math.MaxInt64, 10), int64(math.MaxInt64)},
+		{strconv.FormatInt(math.MinInt64, 10), int64(math.MinInt64)},
+	}
+
+	commonUint64Values = []commonValue{
+		{"0", uint64(0)},
+		{"1337", uint64(1337)},
+		{"0xABC", uint64(0xABC)},
+		{"0b10111011", uint64(0b10111011)},
+		{strconv.FormatUint(math.MaxUint64, 10), uint64(math.MaxUint64)},
+	}
+
+	commonInt32Values = []commonValue{
+		{"0", int32(0)},
+		{"-0", int32(0)},
+		{"1337", int32(1337)},
+		{"-7331", int32(-7331)},
+		{"0xABC", int32(0xABC)},
+		{"-0xCBA", int32(-0xCBA)},
+		{"0b10111011", int32(0b10111011)},
+		{strconv.FormatInt(math.MaxInt32, 10), int32(math.MaxInt32)},
+		{strconv.FormatInt(math.MinInt32, 10), int32(math.MinInt32)},
+	}
+
+	commonUint32Values = []commonValue{
+		{"0", uint32(0)},
+		{"1337", uint32(1337)},
+		{"0xABC", uint32(0xABC)},
+		{"0b10111011", uint32(0b10111011)},
+		{strconv.FormatUint(math.MaxUint32, 10), uint32(math.MaxUint32)},
+	}
+
+	commonInt8Values = []commonValue{
+		{"0", int8(0)},
+		{"-0", int8(0)},
+		{"100", int8(100)},
+		{"-100", int8(-100)},
+		{"0x7F", int8(0x7F)},
+		{"0b1011", int8(0b1011)},
+		{"127", int8(127)},
+		{"-128", int8(-128)},
+	}
+
+	commonInt16Values = []commonValue{
+		{"0", int16(0)},
+		{"-0", int16(0)},
+		{"100", int16(100)},
+		{"-100", int16(-100)},
+		{"0x7F", int16(0x7F)},
+		{"0b1011", int16(0b1011)},
+		{"32767", int16(32767)},
+		{"-32768", int16(-32768)},
+	}
+
+	commonUint8Values = []commonValue{
+		{"0", uint8(0)},
+		{"100", uint8(100)},
+		{"0x7F", uint8(0x7F)},
+		{"0b1011", uint8(0b1011)},
+		{"255", uint8(255)},
+	}
+
+	commonUint16Values = []commonValue{
+		{"0", uint16(0)},
+		{"100", uint16(100)},
+		{"0x7F", uint16(0x7F)},
+		{"0b1011", uint16(0b1011)},
+		{"65535", uint16(65535)},
+	}
+
+	commonFloat32Values = []commonValue{
+		{"0", float32(0.0)},
+		{"-0", float32(0.0)},
+		{"1337", float32(1337.0)},
+		{"-7331", float32(-7331.0)},
+		{"0.5", float32(0.5)},
+		{strconv.FormatFloat(math.MaxFloat32, 'g', -1, 32), float32(math.MaxFloat32)},
+		{strconv.FormatFloat(math.SmallestNonzeroFloat32, 'g', -1, 32), float32(math.SmallestNonzeroFloat32)},
+	}
+
 	commonDurationValues = []commonValue{
 		{"0ns", 0 * time.Nanosecond},
 		{"123ns", 123 * time.Nanosecond},
@@ -98,7 +191,7 @@ const (
 	int32MaxOverflowValue   = "2147483648"           // 2147483647
 	int64MaxOverflowValue   = "9223372036854775808"  // 9223372036854775807
 	int32MinOverflowValue   = "-2147483649"          // -2147483648
-	int64MinOverflowValue   = "9223372036854775809"  // -9223372036854775808
+	int64MinOverflowValue   = "-9223372036854775809" // -9223372036854775808
 	uint32MaxOverflowValue  = "4294967296"           // 4294967295
 	uint64MaxOverflowValue  = "18446744073709551616" // 18446744073709551615
 )
@@ -162,6 +255,97 @@ var (
 		{"uint min overflow", "-0", &ParseValueError{Type: "uint", Err: ErrSyntax}},
 	}
 
+	commonInt64Brokens = []commonBroken{
+		{"empty", "", &ParseValueError{Type: "int64", Err: ErrSyntax}},
+		{"not int64-like", "abcd", &ParseValueError{Type: "int64", Err: ErrSyntax}},
+		{"broken int64", "1337a", &ParseValueError{Type: "int64", Err: ErrSyntax}},
+		{"true", "true", &ParseValueError{Type: "int64", Err: ErrSyntax}},
+		{"false", "false", &ParseValueError{Type: "int64", Err: ErrSyntax}},
+		{"float", "12.34", &ParseValueError{Type: "int64", Err: ErrSyntax}},
+		{"negative float", "-43.21", &ParseValueError{Type: "int64", Err: ErrSyntax}},
+		{"int64 max overflow", int64MaxOverflowValue, &ParseValueError{Type: "int64", Err: ErrRange}},
+		{"int64 min overflow", int64MinOverflowValue, &ParseValueError{Type: "int64", Err: ErrRange}},
+	}
+
+	commonUint64Brokens = []commonBroken{
+		{"empty", "", &ParseValueError{Type: "uint64", Err: ErrSyntax}},
+		{"not uint64-like", "abcd", &ParseValueError{Type: "uint64", Err: ErrSyntax}},
+		{"broken uint64", "1337a", &ParseValueError{Type: "uint64", Err: ErrSyntax}},
+		{"true", "true", &ParseValueError{Type: "uint64", Err: ErrSyntax}},
+		{"false", "false", &ParseValueError{Type: "uint64", Err: ErrSyntax}},
+		{"negative int", "-7331", &ParseValueError{Type: "uint64", Err: ErrSyntax}},
+		{"float", "12.34", &ParseValueError{Type: "uint64", Err: ErrSyntax}},
+		{"negative float", "-43.21", &ParseValueError{Type: "uint64", Err: ErrSyntax}},
+		{"uint64 max overflow", uint64MaxOverflowValue, &ParseValueError{Type: "uint64", Err: ErrRange}},
+		{"uint64 min overflow", "-0", &ParseValueError{Type: "uint64", Err: ErrSyntax}},
+	}
+
+	commonInt32Brokens = []commonBroken{
+		{"empty", "", &ParseValueError{Type: "int32", Err: ErrSyntax}},
+		{"not int32-like", "abcd", &ParseValueError{Type: "int32", Err: ErrSyntax}},
+		{"broken int32", "1337a", &ParseValueError{Type: "int32", Err: ErrSyntax}},
+		{"float", "12.34", &ParseValueError{Type: "int32", Err: ErrSyntax}},
+		{"int32 max overflow", int32MaxOverflowValue, &ParseValueError{Type: "int32", Err: ErrRange}},
+		{"int32 min overflow", int32MinOverflowValue, &ParseValueError{Type: "int32", Err: ErrRange}},
+	}
+
+	commonUint32Brokens = []commonBroken{
+		{"empty", "", &ParseValueError{Type: "uint32", Err: ErrSyntax}},
+		{"not uint32-like", "abcd", &ParseValueError{Type: "uint32", Err: ErrSyntax}},
+		{"broken uint32", "1337a", &ParseValueError{Type: "uint32", Err: ErrSyntax}},
+		{"negative int", "-7331", &ParseValueError{Type: "uint32", Err: ErrSyntax}},
+		{"float", "12.34", &ParseValueError{Type: "uint32", Err: ErrSyntax}},
+		{"uint32 max overflow", uint32MaxOverflowValue, &ParseValueError{Type: "uint32", Err: ErrRange}},
+		{"uint32 min overflow", "-0", &ParseValueError{Type: "uint32", Err: ErrSyntax}},
+	}
+
+	commonInt8Brokens = []commonBroken{
+		{"empty", "", &ParseValueError{Type: "int8", Err: ErrSyntax}},
+		{"not int8-like", "abcd", &ParseValueError{Type: "int8", Err: ErrSyntax}},
+		{"broken int8", "12a", &ParseValueError{Type: "int8", Err: ErrSyntax}},
+		{"float", "12.34", &ParseValueError{Type: "int8", Err: ErrSyntax}},
+		{"int8 max overflow", "128", &ParseValueError{Type: "int8", Err: ErrRange}},
+		{"int8 min overflow", "-129", &ParseValueError{Type: "int8", Err: ErrRange}},
+	}
+
+	commonInt16Brokens = []commonBroken{
+		{"empty", "", &ParseValueError{Type: "int16", Err: ErrSyntax}},
+		{"not int16-like", "abcd", &ParseValueError{Type: "int16", Err: ErrSyntax}},
+		{"broken int16", "12a", &ParseValueError{Type: "int16", Err: ErrSyntax}},
+		{"float", "12.34", &ParseValueError{Type: "int16", Err: ErrSyntax}},
+		{"int16 max overflow", "32768", &ParseValueError{Type: "int16", Err: ErrRange}},
+		{"int16 min overflow", "-32769", &ParseValueError{Type: "int16", Err: ErrRange}},
+	}
+
+	commonUint8Brokens = []commonBroken{
+		{"empty", "", &ParseValueError{Type: "uint8", Err: ErrSyntax}},
+		{"not uint8-like", "abcd", &ParseValueError{Type: "uint8", Err: ErrSyntax}},
+		{"broken uint8", "12a", &ParseValueError{Type: "uint8", Err: ErrSyntax}},
+		{"negative int", "-100", &ParseValueError{Type: "uint8", Err: ErrSyntax}},
+		{"float", "12.34", &ParseValueError{Type: "uint8", Err: ErrSyntax}},
+		{"uint8 max overflow", "256", &ParseValueError{Type: "uint8", Err: ErrRange}},
+		{"uint8 min overflow", "-0", &ParseValueError{Type: "uint8", Err: ErrSyntax}},
+	}
+
+	commonUint16Brokens = []commonBroken{
+		{"empty", "", &ParseValueError{Type: "uint16", Err: ErrSyntax}},
+		{"not uint16-like", "abcd", &ParseValueError{Type: "uint16", Err: ErrSyntax}},
+		{"broken uint16", "12a", &ParseValueError{Type: "uint16", Err: ErrSyntax}},
+		{"negative int", "-100", &ParseValueError{Type: "uint16", Err: ErrSyntax}},
+		{"float", "12.34", &ParseValueError{Type: "uint16", Err: ErrSyntax}},
+		{"uint16 max overflow", "65536", &ParseValueError{Type: "uint16", Err: ErrRange}},
+		{"uint16 min overflow", "-0", &ParseValueError{Type: "uint16", Err: ErrSyntax}},
+	}
+
+	commonFloat32Brokens = []commonBroken{
+		{"empty", "", &ParseValueError{Type: "float32", Err: ErrSyntax}},
+		{"not float32-like", "abcd", &ParseValueError{Type: "float32", Err: ErrSyntax}},
+		{"broken float32", "12.43a", &ParseValueError{Type: "float32", Err: ErrSyntax}},
+		{"true", "true", &ParseValueError{Type: "float32", Err: ErrSyntax}},
+		{"false", "false", &ParseValueError{Type: "float32", Err: ErrSyntax}},
+		{"float32 max overflow", float32MaxOverflowValue, &ParseValueError{Type: "float32", Err: ErrRange}},
+	}
+
 	commonDurationBrokens = []commonBroken{
 		{"empty", "", &ParseValueError{Type: "time.Duration", Err: ErrSyntax}},
 		{"not duration-like", "100", &ParseValueError{Type: "time.Duration", Err: ErrSyntax}},
@@ -367,6 +551,69 @@ func TestParseFlags(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "Int64",
+			setup: func(r Register) interface{} { return Int64(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonInt64Values),
+			),
+		},
+		{
+			name:  "Uint64",
+			setup: func(r Register) interface{} { return Uint64(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonUint64Values),
+			),
+		},
+		{
+			name:  "Int32",
+			setup: func(r Register) interface{} { return Int32(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonInt32Values),
+			),
+		},
+		{
+			name:  "Uint32",
+			setup: func(r Register) interface{} { return Uint32(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonUint32Values),
+			),
+		},
+		{
+			name:  "Int8",
+			setup: func(r Register) interface{} { return Int8(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonInt8Values),
+			),
+		},
+		{
+			name:  "Int16",
+			setup: func(r Register) interface{} { return Int16(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonInt16Values),
+			),
+		},
+		{
+			name:  "Uint8",
+			setup: func(r Register) interface{} { return Uint8(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonUint8Values),
+			),
+		},
+		{
+			name:  "Uint16",
+			setup: func(r Register) interface{} { return Uint16(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonUint16Values),
+			),
+		},
+		{
+			name:  "Float32",
+			setup: func(r Register) interface{} { return Float32(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonFloat32Values),
+			),
+		},
 		{
 			name:  "Duration",
 			setup: func(r Register) interface{} { return Duration(r, "t") },
@@ -563,6 +810,214 @@ func TestParseFlags_broken_value(t *testing.T) {
 			name:  "String",
 			setup: func(r Register) interface{} { return String(r, "t") },
 		},
+		{
+			name:  "String with choices",
+			setup: func(r Register) interface{} { return String(r, "t", WithChoices("json", "yaml")) },
+			tests: []testValue{
+				{
+					name: "value",
+					args: []string{"-t=xml"},
+					want: &FlagError{
+						Short: "t",
+						Err: &ParseValueError{
+							Type: "string",
+							Err:  ErrChoice,
+						},
+					},
+				},
+				{
+					name: "next arg",
+					args: []string{"-t", "xml"},
+					want: &FlagError{
+						Short: "t",
+						Err: &ParseValueError{
+							Type: "string",
+							Err:  ErrChoice,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "Int64",
+			setup: func(r Register) interface{} { return Int64(r, "t") },
+			tests: mergeTestValues(
+				[]testValue{
+					{
+						name: "without value",
+						args: []string{"-t"},
+						want: &FlagError{
+							Short: "t",
+							Err: &ParseValueError{
+								Type: "int64",
+								Err:  ErrSyntax,
+							},
+						},
+					},
+				},
+				commonBrokensToTestValues(commonInt64Brokens),
+			),
+		},
+		{
+			name:  "Uint64",
+			setup: func(r Register) interface{} { return Uint64(r, "t") },
+			tests: mergeTestValues(
+				[]testValue{
+					{
+						name: "without value",
+						args: []string{"-t"},
+						want: &FlagError{
+							Short: "t",
+							Err: &ParseValueError{
+								Type: "uint64",
+								Err:  ErrSyntax,
+							},
+						},
+					},
+				},
+				commonBrokensToTestValues(commonUint64Brokens),
+			),
+		},
+		{
+			name:  "Int32",
+			setup: func(r Register) interface{} { return Int32(r, "t") },
+			tests: mergeTestValues(
+				[]testValue{
+					{
+						name: "without value",
+						args: []string{"-t"},
+						want: &FlagError{
+							Short: "t",
+							Err: &ParseValueError{
+								Type: "int32",
+								Err:  ErrSyntax,
+							},
+						},
+					},
+				},
+				commonBrokensToTestValues(commonInt32Brokens),
+			),
+		},
+		{
+			name:  "Uint32",
+			setup: func(r Register) interface{} { return Uint32(r, "t") },
+			tests: mergeTestValues(
+				[]testValue{
+					{
+						name: "without value",
+						args: []string{"-t"},
+						want: &FlagError{
+							Short: "t",
+							Err: &ParseValueError{
+								Type: "uint32",
+								Err:  ErrSyntax,
+							},
+						},
+					},
+				},
+				commonBrokensToTestValues(commonUint32Brokens),
+			),
+		},
+		{
+			name:  "Int8",
+			setup: func(r Register) interface{} { return Int8(r, "t") },
+			tests: mergeTestValues(
+				[]testValue{
+					{
+						name: "without value",
+						args: []string{"-t"},
+						want: &FlagError{
+							Short: "t",
+							Err: &ParseValueError{
+								Type: "int8",
+								Err:  ErrSyntax,
+							},
+						},
+					},
+				},
+				commonBrokensToTestValues(commonInt8Brokens),
+			),
+		},
+		{
+			name:  "Int16",
+			setup: func(r Register) interface{} { return Int16(r, "t") },
+			tests: mergeTestValues(
+				[]testValue{
+					{
+						name: "without value",
+						args: []string{"-t"},
+						want: &FlagError{
+							Short: "t",
+							Err: &ParseValueError{
+								Type: "int16",
+								Err:  ErrSyntax,
+							},
+						},
+					},
+				},
+				commonBrokensToTestValues(commonInt16Brokens),
+			),
+		},
+		{
+			name:  "Uint8",
+			setup: func(r Register) interface{} { return Uint8(r, "t") },
+			tests: mergeTestValues(
+				[]testValue{
+					{
+						name: "without value",
+						args: []string{"-t"},
+						want: &FlagError{
+							Short: "t",
+							Err: &ParseValueError{
+								Type: "uint8",
+								Err:  ErrSyntax,
+							},
+						},
+					},
+				},
+				commonBrokensToTestValues(commonUint8Brokens),
+			),
+		},
+		{
+			name:  "Uint16",
+			setup: func(r Register) interface{} { return Uint16(r, "t") },
+			tests: mergeTestValues(
+				[]testValue{
+					{
+						name: "without value",
+						args: []string{"-t"},
+						want: &FlagError{
+							Short: "t",
+							Err: &ParseValueError{
+								Type: "uint16",
+								Err:  ErrSyntax,
+							},
+						},
+					},
+				},
+				commonBrokensToTestValues(commonUint16Brokens),
+			),
+		},
+		{
+			name:  "Float32",
+			setup: func(r Register) interface{} { return Float32(r, "t") },
+			tests: mergeTestValues(
+				[]testValue{
+					{
+						name: "without value",
+						args: []string{"-t"},
+						want: &FlagError{
+							Short: "t",
+							Err: &ParseValueError{
+								Type: "float32",
+								Err:  ErrSyntax,
+							},
+						},
+					},
+				},
+				commonBrokensToTestValues(commonFloat32Brokens),
+			),
+		},
 		{
 			name:  "Duration",
 			setup: func(r Register) interface{} { return Duration(r, "t") },
@@ -740,6 +1195,69 @@ func TestParseArgs(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "Int64Arg",
+			setup: func(r Register) interface{} { return Int64Arg(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonInt64Values),
+			),
+		},
+		{
+			name:  "Uint64Arg",
+			setup: func(r Register) interface{} { return Uint64Arg(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonUint64Values),
+			),
+		},
+		{
+			name:  "Int32Arg",
+			setup: func(r Register) interface{} { return Int32Arg(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonInt32Values),
+			),
+		},
+		{
+			name:  "Uint32Arg",
+			setup: func(r Register) interface{} { return Uint32Arg(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonUint32Values),
+			),
+		},
+		{
+			name:  "Int8Arg",
+			setup: func(r Register) interface{} { return Int8Arg(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonInt8Values),
+			),
+		},
+		{
+			name:  "Int16Arg",
+			setup: func(r Register) interface{} { return Int16Arg(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonInt16Values),
+			),
+		},
+		{
+			name:  "Uint8Arg",
+			setup: func(r Register) interface{} { return Uint8Arg(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonUint8Values),
+			),
+		},
+		{
+			name:  "Uint16Arg",
+			setup: func(r Register) interface{} { return Uint16Arg(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonUint16Values),
+			),
+		},
+		{
+			name:  "Float32Arg",
+			setup: func(r Register) interface{} { return Float32Arg(r, "t") },
+			tests: mergeTestValues(
+				commonValuesToTestValues(commonFloat32Values),
+			),
+		},
 		{
 			name:  "DurationArg",
 			setup: func(r Register) interface{} { return DurationArg(r, "t") },
@@ -886,29 +1404,120 @@ func TestParseArgs_broken_value(t *testing.T) {
 			setup: func(r Register) interface{} { return StringArg(r, "t") },
 		},
 		{
-			name:  "DurationArg",
-			setup: func(r Register) interface{} { return DurationArg(r, "t") },
+			name:  "Int64Arg",
+			setup: func(r Register) interface{} { return Int64Arg(r, "t") },
 			tests: mergeTestValues(
-				commonBrokensToTestValues(commonDurationBrokens),
+				commonBrokensToTestValues(commonInt64Brokens),
 			),
 		},
-	}
-
-	for _, tc := range tt {
-		t.Run(tc.name, func(t *testing.T) {
-			for _, tvc := range tc.tests {
-				t.Run(tvc.name, func(t *testing.T) {
-					var (
-						register DefaultRegister
-						parser   DefaultParser
-					)
-
-					_ = tc.setup(&register)
-
-					err := parser.Parse(nil, &register, tvc.args)
-					if !errors.Is(err, tvc.want) {
+		{
+			name:  "Uint64Arg",
+			setup: func(r Register) interface{} { return Uint64Arg(r, "t") },
+			tests: mergeTestValues(
+				commonBrokensToTestValues(commonUint64Brokens),
+			),
+		},
+		{
+			name:  "Int32Arg",
+			setup: func(r Register) interface{} { return Int32Arg(r, "t") },
+			tests: mergeTestValues(
+				commonBrokensToTestValues(commonInt32Brokens),
+			),
+		},
+		{
+			name:  "Uint32Arg",
+			setup: func(r Register) interface{} { return Uint32Arg(r, "t") },
+			tests: mergeTestValues(
+				commonBrokensToTestValues(commonUint32Brokens),
+			),
+		},
+		{
+			name:  "Int8Arg",
+			setup: func(r Register) interface{} { return Int8Arg(r, "t") },
+			tests: mergeTestValues(
+				commonBrokensToTestValues(commonInt8Brokens),
+			),
+		},
+		{
+			name:  "Int16Arg",
+			setup: func(r Register) interface{} { return Int16Arg(r, "t") },
+			tests: mergeTestValues(
+				commonBrokensToTestValues(commonInt16Brokens),
+			),
+		},
+		{
+			name:  "Uint8Arg",
+			setup: func(r Register) interface{} { return Uint8Arg(r, "t") },
+			tests: mergeTestValues(
+				commonBrokensToTestValues(commonUint8Brokens),
+			),
+		},
+		{
+			name:  "Uint16Arg",
+			setup: func(r Register) interface{} { return Uint16Arg(r, "t") },
+			tests: mergeTestValues(
+				commonBrokensToTestValues(commonUint16Brokens),
+			),
+		},
+		{
+			name:  "Float32Arg",
+			setup: func(r Register) interface{} { return Float32Arg(r, "t") },
+			tests: mergeTestValues(
+				commonBrokensToTestValues(commonFloat32Brokens),
+			),
+		},
+		{
+			name:  "DurationArg",
+			setup: func(r Register) interface{} { return DurationArg(r, "t") },
+			tests: mergeTestValues(
+				commonBrokensToTestValues(commonDurationBrokens),
+			),
+		},
+		{
+			name: "IntArg after args",
+			setup: func(r Register) interface{} {
+				_ = StringArg(r, "first")
+				_ = StringArg(r, "second")
+				return IntArg(r, "t")
+			},
+			tests: []testValue{
+				{
+					name: "third",
+					args: []string{"a", "b", "1337a"},
+					want: &ArgError{
+						Name:  "t",
+						Index: 2,
+						Err: &ParseValueError{
+							Type: "int",
+							Err:  ErrSyntax,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, tvc := range tc.tests {
+				t.Run(tvc.name, func(t *testing.T) {
+					var (
+						register DefaultRegister
+						parser   DefaultParser
+					)
+
+					_ = tc.setup(&register)
+
+					err := parser.Parse(nil, &register, tvc.args)
+					if !errors.Is(err, tvc.want) {
 						t.Fatalf("Parse(%v): got error = %q, want error = %q", tvc.args, err, tvc.want)
 					}
+
+					// Position of the failing arg must be reported.
+					var argErr *ArgError
+					if !errors.As(err, &argErr) || argErr.Position != tvc.want.(*ArgError).Index {
+						t.Errorf("Parse(%v): got error = %#v, want position = %d", tvc.args, err, tvc.want.(*ArgError).Index)
+					}
 				})
 			}
 		})
@@ -1001,6 +1610,164 @@ func TestRegisterInvalidNameFlag(t *testing.T) {
 	}
 }
 
+func TestRegisterInvalidNameFlag_with_required(t *testing.T) {
+	var register DefaultRegister
+
+	_ = Bool(&register, "", WithShort("he"), WithLong("help"), WithRequired())
+
+	want := &FlagError{Short: "he", Long: "help", Err: ErrInvalidName}
+	if got := register.Err(); !errors.Is(got, want) {
+		t.Fatalf("Err(): got error = %q, want error = %q", got, want)
+	}
+}
+
+func TestRegisterFlag_with_required(t *testing.T) {
+	var register DefaultRegister
+
+	_ = Bool(&register, "a", WithRequired())
+	_ = BoolArg(&register, "b", WithOptional())
+
+	if flag, _ := register.ShortFlag("a"); !flag.Required() {
+		t.Errorf("Required(): got = false, want = true")
+	}
+
+	if arg, _ := register.Arg(0); arg.Required() {
+		t.Errorf("Required(): got = true, want = false")
+	}
+}
+
+func TestRegisterInvalidNameFlag_error_at_registration(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		help     bool
+	)
+
+	want := &FlagError{Short: "he", Long: "help", Err: ErrInvalidName}
+
+	// Registration errors are returned eagerly from *Var functions.
+	got := BoolVar(&register, &help, "help", WithShort("he"))
+	if !errors.Is(got, want) {
+		t.Fatalf("BoolVar(): got error = %q, want error = %q", got, want)
+	}
+
+	// And they are kept in the register for users who ignored them.
+	if got := register.Err(); !errors.Is(got, want) {
+		t.Fatalf("Err(): got error = %q, want error = %q", got, want)
+	}
+
+	if got := parser.Parse(nil, &register, nil); !errors.Is(got, want) {
+		t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+	}
+}
+
+func TestVerifyFlagOptions(t *testing.T) {
+	tt := []struct {
+		name string
+		opts FlagOptions
+		want error
+	}{
+		{
+			name: "valid",
+			opts: FlagOptions{Short: "h", Long: "help"},
+			want: nil,
+		},
+		{
+			name: "empty short and long names",
+			opts: FlagOptions{},
+			want: &FlagError{Err: ErrMissingName},
+		},
+		{
+			name: "too long short name",
+			opts: FlagOptions{Short: "he"},
+			want: &FlagError{Short: "he", Err: ErrInvalidName},
+		},
+		{
+			name: "start dash in long name",
+			opts: FlagOptions{Short: "h", Long: "-help"},
+			want: &FlagError{Short: "h", Long: "-help", Err: ErrInvalidName},
+		},
+		{
+			name: "valid value",
+			opts: FlagOptions{Long: "verbose", Value: newBoolValue(new(bool))},
+			want: nil,
+		},
+		{
+			name: "nil target",
+			opts: FlagOptions{Long: "verbose", Value: newBoolValue(nil)},
+			want: &FlagError{Long: "verbose", Err: ErrNilTarget},
+		},
+		{
+			name: "short only without short name",
+			opts: FlagOptions{Long: "verbose", ShortOnly: true},
+			want: &FlagError{Long: "verbose", Err: ErrMissingName},
+		},
+		{
+			name: "long only without long name",
+			opts: FlagOptions{Short: "v", LongOnly: true},
+			want: &FlagError{Short: "v", Err: ErrMissingName},
+		},
+		{
+			name: "short only and long only",
+			opts: FlagOptions{Short: "v", Long: "verbose", ShortOnly: true, LongOnly: true},
+			want: &FlagError{Short: "v", Long: "verbose", Err: ErrConflict},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := VerifyFlagOptions(tc.opts)
+			if !errors.Is(got, tc.want) {
+				t.Fatalf("VerifyFlagOptions(): got error = %q, want error = %q", got, tc.want)
+			}
+		})
+	}
+
+	// It must not have side effects.
+	var register DefaultRegister
+
+	opts := FlagOptions{Short: "h", Long: "help"}
+	_ = VerifyFlagOptions(opts)
+	_ = VerifyFlagOptions(opts)
+
+	if err := BoolVar(&register, new(bool), "", opts); err != nil {
+		t.Fatalf("BoolVar(): failed to register the flag after verification: %s", err)
+	}
+}
+
+func TestValidLongFlag(t *testing.T) {
+	tt := []struct {
+		name string
+		want bool
+	}{
+		{name: "help", want: true},
+		// Trailing dashes.
+		{name: "help-", want: true},
+		{name: "help--", want: true},
+		{name: "ab-", want: true},
+		// A dash right after the first byte is rejected even at the end.
+		{name: "a-", want: false},
+		// The "second dash" rule.
+		{name: "a-b", want: false},
+		{name: "a-b-", want: false},
+		{name: "a--b", want: false},
+		{name: "ab-c-", want: true},
+		{name: "ab--c", want: true},
+		{name: "go-help", want: true},
+		// The rule is byte based, the second byte of "ф" is not a dash.
+		{name: "ф-", want: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := validLongFlag(tc.name)
+			if got != tc.want {
+				t.Errorf("validLongFlag(%q): got = %v, want = %v", tc.name, got, tc.want)
+			}
+		})
+	}
+}
+
 func TestRegisterDuplicatedFlag(t *testing.T) {
 	var register DefaultRegister
 
@@ -1713,127 +2480,410 @@ func TestParser_Parse_ignore_unknown_flags(t *testing.T) {
 	}
 }
 
-func TestParser_Parse_unknown_rest(t *testing.T) {
-	var (
-		register DefaultRegister
-		parser   DefaultParser
-	)
-
-	_ = BoolArg(&register, "a")
-	_ = BoolArg(&register, "b", Optional)
-
-	args := []string{"true", "false", "c", "d", "1337", "false", "e"}
-
-	got := parser.Parse(nil, &register, args)
-	want := &ParseArgError{Arg: "c", Index: 2, Err: ErrUnknown}
-	if !errors.Is(got, want) {
-		t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
-	}
-}
-
-func TestParser_Parse_ignore_unknown_rest(t *testing.T) {
+func TestParser_Unknown(t *testing.T) {
 	var register DefaultRegister
 	parser := DefaultParser{
-		IgnoreUnknownArgs: true,
+		IgnoreUnknownFlags: true,
+		IgnoreUnknownArgs:  true,
 	}
 
-	a := BoolArg(&register, "a")
-	b := BoolArg(&register, "b")
+	_ = Bool(&register, "a")
+	_ = BoolArg(&register, "v")
 
-	args := []string{"true", "false", "c", "d", "1337", "false", "e"}
+	args := []string{"-a", "-c=200", "--first-unknown", "false", "-de", "extra", "vals"}
 
 	if err := parser.Parse(nil, &register, args); err != nil {
 		t.Fatalf("Parse(): failed to parse args: %s", err)
 	}
 
-	const (
-		wantA = true
-		wantB = false
-	)
-
-	if *a != wantA {
-		t.Errorf("Parse(): a: got = %v, want = %v", *a, wantA)
+	wantUnknown := []string{"-c", "--first-unknown", "-d", "-e"}
+	if got := parser.Unknown(); !reflect.DeepEqual(got, wantUnknown) {
+		t.Errorf("Unknown(): got = %#v, want = %#v", got, wantUnknown)
 	}
 
-	if *b != wantB {
-		t.Errorf("Parse(): b: got = %v, want = %v", *b, wantB)
+	wantRest := []string{"extra", "vals"}
+	if got := parser.RestArgs(); !reflect.DeepEqual(got, wantRest) {
+		t.Errorf("RestArgs(): got = %#v, want = %#v", got, wantRest)
 	}
-}
 
-func TestParser_Parse_rest_with_ignore_unknown_rest(t *testing.T) {
-	var register DefaultRegister
-	parser := DefaultParser{
-		IgnoreUnknownArgs: true,
+	// Returned slices are copies.
+	parser.Unknown()[0] = "changed"
+	if got := parser.Unknown(); !reflect.DeepEqual(got, wantUnknown) {
+		t.Errorf("Unknown(): got = %#v, want = %#v", got, wantUnknown)
 	}
 
-	a := BoolArg(&register, "a")
-	b := BoolArg(&register, "b")
-
-	var rest []string
-	_ = RestStringsVar(&register, &rest, "rest")
+	// Reset by the next parse.
+	if err := parser.Parse(nil, &register, []string{"true"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
 
-	args := []string{"true", "false", "c", "d", "1337", "false", "e"}
+	if got := parser.Unknown(); len(got) != 0 {
+		t.Errorf("Unknown(): got = %#v, want empty", got)
+	}
 
-	if err := parser.Parse(nil, &register, args); err != nil {
-		t.Fatalf("Parse(): failed to parse args: %s", err)
+	if got := parser.RestArgs(); len(got) != 0 {
+		t.Errorf("RestArgs(): got = %#v, want empty", got)
 	}
+}
 
-	const (
-		wantA = true
-		wantB = false
+func TestParser_RestArgs(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
 	)
 
-	wantRest := []string{"c", "d", "1337", "false", "e"}
+	_ = BoolArg(&register, "a")
+	rest := RestInts(&register, "rest")
 
-	if *a != wantA {
-		t.Errorf("Parse(): a: got = %v, want = %v", *a, wantA)
+	args := []string{"true", "1", "--", "-2"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
 	}
 
-	if *b != wantB {
-		t.Errorf("Parse(): b: got = %v, want = %v", *b, wantB)
+	want := []string{"1", "-2"}
+	if got := parser.RestArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RestArgs(): got = %#v, want = %#v", got, want)
 	}
 
-	if !reflect.DeepEqual(rest, wantRest) {
-		t.Errorf("Parse(): rest: got = %#v, want = %#v", rest, wantRest)
+	if !reflect.DeepEqual(*rest, []int{1, -2}) {
+		t.Errorf("Parse(): rest: got = %#v, want = %#v", *rest, []int{1, -2})
 	}
 }
 
-var _ Commander = (*testCommander)(nil)
-
-type testCommander struct {
-	commands []string
-	use      func() (Register, error)
+func TestParser_Lookup(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
 
-	path []string
-	i    int
-}
+	_ = Bool(&register, "verbose", WithShort("v"))
+	_ = Int(&register, "n")
+	_ = StringArg(&register, "name")
 
-func (c *testCommander) IsCommand(name string) bool {
-	if c.i >= len(c.commands) {
-		return false
+	if got := parser.Lookup("verbose"); got != nil {
+		t.Errorf("Lookup(%q): got = %v, want = nil before the Parse", "verbose", got)
 	}
 
-	cmd := c.commands[c.i]
+	args := []string{"-v", "-n", "10", "test"}
 
-	return cmd == name
-}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
 
-func (c *testCommander) SetCommand(name string) (Register, error) {
-	if c.i >= len(c.commands) {
-		return nil, fmt.Errorf("command not found: %s", name)
+	for _, name := range []string{"verbose", "v"} {
+		flag := parser.Lookup(name)
+		if flag == nil || flag.Long != "verbose" {
+			t.Fatalf("Lookup(%q): got = %v, want verbose flag", name, flag)
+		}
+
+		if got := flag.Value.String(); got != "true" {
+			t.Errorf("Lookup(%q).Value: got = %q, want = %q", name, got, "true")
+		}
 	}
 
-	cmd := c.commands[c.i]
-	if cmd != name {
-		return nil, fmt.Errorf("command not found: %s", name)
+	if flag := parser.Lookup("n"); flag == nil || flag.Value.String() != "10" {
+		t.Errorf("Lookup(%q): got = %v, want n flag with value %q", "n", flag, "10")
 	}
 
-	c.i++
-	c.path = append(c.path, cmd)
+	if got := parser.Lookup("unknown"); got != nil {
+		t.Errorf("Lookup(%q): got = %v, want = nil", "unknown", got)
+	}
 
-	register, err := c.use()
-	if err != nil {
-		return nil, err
+	if arg := parser.LookupArg("name"); arg == nil || arg.Value.String() != "test" {
+		t.Errorf("LookupArg(%q): got = %v, want name arg with value %q", "name", arg, "test")
+	}
+
+	if got := parser.LookupArg("unknown"); got != nil {
+		t.Errorf("LookupArg(%q): got = %v, want = nil", "unknown", got)
+	}
+}
+
+func TestParser_Visit(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Bool(&register, "a")
+	_ = Bool(&register, "b")
+	_ = Int(&register, "c")
+	_ = StringArg(&register, "x")
+	_ = StringArg(&register, "y", Optional)
+
+	args := []string{"-c", "1", "-a", "test"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	var got []string
+	record := func(name string, set bool) {
+		got = append(got, name+"="+strconv.FormatBool(set))
+	}
+
+	tt := []struct {
+		name  string
+		visit func()
+		want  []string
+	}{
+		{
+			name:  "Visit",
+			visit: func() { parser.Visit(func(f *Flag, set bool) { record(f.Short, set) }) },
+			want:  []string{"a=true", "c=true"},
+		},
+		{
+			name:  "VisitAll",
+			visit: func() { parser.VisitAll(func(f *Flag, set bool) { record(f.Short, set) }) },
+			want:  []string{"a=true", "b=false", "c=true"},
+		},
+		{
+			name:  "VisitArgs",
+			visit: func() { parser.VisitArgs(func(a *Arg, set bool) { record(a.Name, set) }) },
+			want:  []string{"x=true"},
+		},
+		{
+			name:  "VisitAllArgs",
+			visit: func() { parser.VisitAllArgs(func(a *Arg, set bool) { record(a.Name, set) }) },
+			want:  []string{"x=true", "y=false"},
+		},
+	}
+
+	for _, tc := range tt {
+		got = nil
+		tc.visit()
+
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s(): got = %v, want = %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParser_Parse_with_ref(t *testing.T) {
+	var (
+		register           DefaultRegister
+		parser             DefaultParser
+		portRef, debugRef  FlagRef
+		emptyRef, firstRef FlagRef
+	)
+
+	port := Int(&register, "port", WithDefault("8080"), WithRef(&portRef), WithRef(&firstRef))
+	_ = Bool(&register, "d", WithRef(&debugRef))
+
+	// Next registrations must not break the refs.
+	for i := 0; i < 16; i++ {
+		_ = Bool(&register, "flag-"+strconv.Itoa(i))
+	}
+
+	args := []string{"--port", "8080"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if *port != 8080 {
+		t.Errorf("Parse(): port: got = %d, want = %d", *port, 8080)
+	}
+
+	if !portRef.IsSet() || !firstRef.IsSet() {
+		t.Errorf("IsSet(): port: got = false, want = true")
+	}
+
+	if debugRef.IsSet() {
+		t.Errorf("IsSet(): d: got = true, want = false")
+	}
+
+	if flag := debugRef.Flag(); flag == nil || flag.Short != "d" {
+		t.Errorf("Flag(): got = %v, want d flag", flag)
+	}
+
+	if emptyRef.IsSet() || emptyRef.Flag() != nil {
+		t.Errorf("IsSet(): empty ref: got = true, want = false")
+	}
+}
+
+func TestParser_Parse_unknown_rest(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = BoolArg(&register, "a")
+	_ = BoolArg(&register, "b", Optional)
+
+	args := []string{"true", "false", "c", "d", "1337", "false", "e"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &ParseArgError{Arg: "c", Index: 2, Err: ErrUnknown}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+	}
+
+	if pe := got.(*ParseArgError); pe.Position != 2 {
+		t.Errorf("Parse(): got position = %d, want = %d", pe.Position, 2)
+	}
+}
+
+func TestParser_Parse_ignore_unknown_rest(t *testing.T) {
+	var register DefaultRegister
+	parser := DefaultParser{
+		IgnoreUnknownArgs: true,
+	}
+
+	a := BoolArg(&register, "a")
+	b := BoolArg(&register, "b")
+
+	args := []string{"true", "false", "c", "d", "1337", "false", "e"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	const (
+		wantA = true
+		wantB = false
+	)
+
+	if *a != wantA {
+		t.Errorf("Parse(): a: got = %v, want = %v", *a, wantA)
+	}
+
+	if *b != wantB {
+		t.Errorf("Parse(): b: got = %v, want = %v", *b, wantB)
+	}
+}
+
+func TestParser_Parse_rest_with_ignore_unknown_rest(t *testing.T) {
+	var register DefaultRegister
+	parser := DefaultParser{
+		IgnoreUnknownArgs: true,
+	}
+
+	a := BoolArg(&register, "a")
+	b := BoolArg(&register, "b")
+
+	var rest []string
+	_ = RestStringsVar(&register, &rest, "rest")
+
+	args := []string{"true", "false", "c", "d", "1337", "false", "e"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	const (
+		wantA = true
+		wantB = false
+	)
+
+	wantRest := []string{"c", "d", "1337", "false", "e"}
+
+	if *a != wantA {
+		t.Errorf("Parse(): a: got = %v, want = %v", *a, wantA)
+	}
+
+	if *b != wantB {
+		t.Errorf("Parse(): b: got = %v, want = %v", *b, wantB)
+	}
+
+	if !reflect.DeepEqual(rest, wantRest) {
+		t.Errorf("Parse(): rest: got = %#v, want = %#v", rest, wantRest)
+	}
+}
+
+func TestParser_Parse_ignore_unknown_args_with_required_arg(t *testing.T) {
+	parser := DefaultParser{
+		IgnoreUnknownArgs: true,
+	}
+
+	t.Run("extra arg", func(t *testing.T) {
+		var register DefaultRegister
+
+		count := IntArg(&register, "count")
+
+		args := []string{"100", "extra"}
+
+		if err := parser.Parse(nil, &register, args); err != nil {
+			t.Fatalf("Parse(): failed to parse args: %s", err)
+		}
+
+		const wantCount = 100
+		if *count != wantCount {
+			t.Errorf("Parse(): count: got = %v, want = %v", *count, wantCount)
+		}
+	})
+
+	t.Run("broken required arg", func(t *testing.T) {
+		var register DefaultRegister
+
+		_ = IntArg(&register, "count")
+
+		// The first arg always fills the first slot, so it's a value error
+		// and not an ignored unknown arg.
+		args := []string{"extra"}
+
+		got := parser.Parse(nil, &register, args)
+		want := &ArgError{
+			Name: "count",
+			Err: &ParseValueError{
+				Type: "int",
+				Err:  ErrSyntax,
+			},
+		}
+		if !errors.Is(got, want) {
+			t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+		}
+	})
+
+	t.Run("missing required arg", func(t *testing.T) {
+		var register DefaultRegister
+
+		_ = IntArg(&register, "count")
+
+		got := parser.Parse(nil, &register, nil)
+		want := &ArgError{Name: "count", Err: ErrNotProvided}
+		if !errors.Is(got, want) {
+			t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+		}
+	})
+}
+
+var _ Commander = (*testCommander)(nil)
+
+type testCommander struct {
+	commands []string
+	use      func() (Register, error)
+
+	path []string
+	i    int
+}
+
+func (c *testCommander) IsCommand(name string) bool {
+	if c.i >= len(c.commands) {
+		return false
+	}
+
+	cmd := c.commands[c.i]
+
+	return cmd == name
+}
+
+func (c *testCommander) SetCommand(name string) (Register, error) {
+	if c.i >= len(c.commands) {
+		return nil, fmt.Errorf("command not found: %s", name)
+	}
+
+	cmd := c.commands[c.i]
+	if cmd != name {
+		return nil, fmt.Errorf("command not found: %s", name)
+	}
+
+	c.i++
+	c.path = append(c.path, cmd)
+
+	register, err := c.use()
+	if err != nil {
+		return nil, err
 	}
 
 	return register, nil
@@ -1951,56 +3001,194 @@ func TestParser_Parse_with_commands(t *testing.T) {
 	}
 }
 
-func TestParser_Parse_required_flag(t *testing.T) {
+func TestParser_Parse_twice(t *testing.T) {
 	var (
-		register DefaultRegister
 		parser   DefaultParser
+		register DefaultRegister
+		release  *bool
+		verbose  *bool
 	)
 
-	_ = Bool(&register, "a")
-	_ = Bool(&register, "b", Required)
-	_ = Bool(&register, "c")
+	cmder := NewStaticCommander(map[string]func(Register) error{
+		"build": func(r Register) error {
+			release = Bool(r, "release")
+			return nil
+		},
+		"test": func(r Register) error {
+			verbose = Bool(r, "verbose")
+			return nil
+		},
+	})
 
-	args := []string{"-a"}
+	args := []string{"build", "--release"}
+	if err := parser.Parse(cmder, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
 
-	got := parser.Parse(nil, &register, args)
-	want := &FlagError{Short: "b", Err: ErrNotProvided}
-	if !errors.Is(got, want) {
-		t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+	if want := []string{"build"}; !reflect.DeepEqual(parser.ParsedCommands(), want) {
+		t.Errorf("ParsedCommands(): got = %v, want = %v", parser.ParsedCommands(), want)
+	}
+
+	if !*release {
+		t.Errorf("Parse(%v): release: got = %v, want = %v", args, *release, true)
+	}
+
+	// The same commander must not carry the path over from the previous call.
+	args = []string{"test", "--verbose"}
+	if err := parser.Parse(cmder, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if want := []string{"test"}; !reflect.DeepEqual(parser.ParsedCommands(), want) {
+		t.Errorf("ParsedCommands(): got = %v, want = %v", parser.ParsedCommands(), want)
+	}
+
+	if want := []string{"test"}; !reflect.DeepEqual(cmder.Path(), want) {
+		t.Errorf("Path(): got = %v, want = %v", cmder.Path(), want)
+	}
+
+	if !*verbose {
+		t.Errorf("Parse(%v): verbose: got = %v, want = %v", args, *verbose, true)
 	}
 }
 
-func TestParser_Parse_required_multi_flag(t *testing.T) {
+func TestParser_Parse_twice_tree(t *testing.T) {
 	var (
-		register DefaultRegister
 		parser   DefaultParser
+		register DefaultRegister
 	)
 
-	_ = Bool(&register, "a")
-	_ = Bools(&register, "b")
-	_ = Bools(&register, "c", Required)
-	_ = Bools(&register, "d", Required)
-	_ = Bools(&register, "e")
+	cmder := NewTreeCommander(&TreeNode{
+		Children: map[string]*TreeNode{
+			"remote": {
+				Children: map[string]*TreeNode{
+					"add": {},
+				},
+			},
+			"status": {},
+		},
+	})
 
-	args := []string{"-a", "-c"}
+	args := []string{"remote", "add"}
+	if err := parser.Parse(cmder, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
 
-	got := parser.Parse(nil, &register, args)
-	want := &FlagError{Short: "d", Err: ErrNotProvided}
-	if !errors.Is(got, want) {
-		t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+	args = []string{"status"}
+	if err := parser.Parse(cmder, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if want := []string{"status"}; !reflect.DeepEqual(parser.ParsedCommands(), want) {
+		t.Errorf("ParsedCommands(): got = %v, want = %v", parser.ParsedCommands(), want)
 	}
 }
 
-func TestParser_Parse_required_arg(t *testing.T) {
+func TestParser_Parse_many_flags(t *testing.T) {
+	const numFlags = 1000
+
 	var (
 		register DefaultRegister
 		parser   DefaultParser
 	)
 
-	// Args are implicitly required.
-	_ = BoolArg(&register, "a")
-	_ = BoolArg(&register, "b")
-	_ = BoolArg(&register, "c")
+	values := make([]*int, numFlags)
+	args := make([]string, 0, numFlags*2)
+	for i := 0; i < numFlags; i++ {
+		name := "flag-" + strconv.Itoa(i)
+
+		values[i] = Int(&register, name, Required)
+		args = append(args, "--"+name, strconv.Itoa(i))
+	}
+
+	start := time.Now()
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	// Lookups are map based and the required check is linear, so it must be
+	// fast even with the race detector.
+	const maxElapsed = 100 * time.Millisecond
+	if elapsed := time.Since(start); elapsed > maxElapsed {
+		t.Errorf("Parse(): took %s, want less than %s", elapsed, maxElapsed)
+	}
+
+	for i, v := range values {
+		if *v != i {
+			t.Errorf("Parse(): flag-%d: got = %v, want = %v", i, *v, i)
+		}
+	}
+}
+
+func TestParser_Parse_required_flag(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Bool(&register, "a")
+	_ = Bool(&register, "b", Required)
+	_ = Bool(&register, "c")
+
+	args := []string{"-a"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &FlagError{Short: "b", Err: ErrNotProvided}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+	}
+}
+
+func TestParser_Parse_with_required_flag(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Bool(&register, "a")
+	_ = Bool(&register, "b", WithRequired())
+
+	args := []string{"-a"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &FlagError{Short: "b", Err: ErrNotProvided}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+	}
+}
+
+func TestParser_Parse_required_multi_flag(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Bool(&register, "a")
+	_ = Bools(&register, "b")
+	_ = Bools(&register, "c", Required)
+	_ = Bools(&register, "d", Required)
+	_ = Bools(&register, "e")
+
+	args := []string{"-a", "-c"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &FlagError{Short: "d", Err: ErrNotProvided}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+	}
+}
+
+func TestParser_Parse_required_arg(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	// Args are implicitly required.
+	_ = BoolArg(&register, "a")
+	_ = BoolArg(&register, "b")
+	_ = BoolArg(&register, "c")
 	_ = BoolArg(&register, "d")
 
 	args := []string{"true", "false"}
@@ -2075,6 +3263,188 @@ func TestParser_Parse_rest(t *testing.T) {
 	}
 }
 
+func manyRestArgs(n int) []string {
+	args := make([]string, n)
+	for i := range args {
+		args[i] = "arg-" + strconv.Itoa(i)
+	}
+
+	return args
+}
+
+func TestParser_Parse_many_rest_args(t *testing.T) {
+	const numArgs = 1000
+
+	args := manyRestArgs(numArgs)
+
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		rest     []string
+	)
+
+	_ = RestStringsVar(&register, &rest, "rest")
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if !reflect.DeepEqual(rest, args) {
+		t.Fatalf("Parse(): rest: got %d values, want %d values", len(rest), len(args))
+	}
+
+	// Rest args are accumulated with append, so the number of allocations
+	// must grow logarithmically and not linearly.
+	allocs := testing.AllocsPerRun(10, func() {
+		var (
+			register DefaultRegister
+			rest     []string
+		)
+
+		_ = RestStringsVar(&register, &rest, "rest")
+		_ = parser.Parse(nil, &register, args)
+	})
+
+	const maxAllocs = numArgs / 10
+	if allocs > maxAllocs {
+		t.Errorf("Parse(): got %v allocs, want less than %v", allocs, maxAllocs)
+	}
+}
+
+func BenchmarkParse_1000_rest_args(b *testing.B) {
+	args := manyRestArgs(1000)
+
+	var parser DefaultParser
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var (
+			register DefaultRegister
+			rest     []string
+		)
+
+		_ = RestStringsVar(&register, &rest, "rest")
+
+		if err := parser.Parse(nil, &register, args); err != nil {
+			b.Fatalf("Parse(): failed to parse args: %s", err)
+		}
+	}
+}
+
+func benchmarkFlagNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = "flag-" + strconv.Itoa(i)
+	}
+
+	return names
+}
+
+func BenchmarkRegisterFlag(b *testing.B) {
+	names := benchmarkFlagNames(64)
+	values := make([]bool, len(names))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var register DefaultRegister
+
+		for j, name := range names {
+			if err := register.RegisterFlag(Flag{Value: newBoolValue(&values[j]), Long: name}); err != nil {
+				b.Fatalf("RegisterFlag(): failed to register flag: %s", err)
+			}
+		}
+	}
+}
+
+func BenchmarkRegisterFlag_reserved(b *testing.B) {
+	names := benchmarkFlagNames(64)
+	values := make([]bool, len(names))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var register DefaultRegister
+		register.ReserveFlags(len(names))
+
+		for j, name := range names {
+			if err := register.RegisterFlag(Flag{Value: newBoolValue(&values[j]), Long: name}); err != nil {
+				b.Fatalf("RegisterFlag(): failed to register flag: %s", err)
+			}
+		}
+	}
+}
+
+func benchmarkParse(b *testing.B, numFlags, numSet int) {
+	names := benchmarkFlagNames(numFlags)
+	values := make([]int, len(names))
+
+	args := make([]string, 0, numSet*2)
+	for _, name := range names[:numSet] {
+		args = append(args, "--"+name, "1337")
+	}
+
+	var parser DefaultParser
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var register DefaultRegister
+
+		for j, name := range names {
+			_ = IntVar(&register, &values[j], name)
+		}
+
+		if err := parser.Parse(nil, &register, args); err != nil {
+			b.Fatalf("Parse(): failed to parse args: %s", err)
+		}
+	}
+}
+
+func BenchmarkParse_small(b *testing.B) { benchmarkParse(b, 8, 8) }
+
+func BenchmarkParse_large(b *testing.B) { benchmarkParse(b, 64, 32) }
+
+func benchmarkFlags(n int) *flags {
+	var f flags
+	for i, name := range benchmarkFlagNames(n) {
+		f.Add(Flag{Long: name, Short: string(rune('A' + i%26))})
+	}
+
+	return &f
+}
+
+func BenchmarkFlagLookup_long(b *testing.B) {
+	f := benchmarkFlags(64)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, ok := f.LongFlag("flag-42"); !ok {
+			b.Fatalf("LongFlag(): flag not found")
+		}
+	}
+}
+
+func BenchmarkFlagLookup_short(b *testing.B) {
+	f := benchmarkFlags(64)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, ok := f.ShortFlag("Q"); !ok {
+			b.Fatalf("ShortFlag(): flag not found")
+		}
+	}
+}
+
 func TestRegisterInvalidNameRestArgs(t *testing.T) {
 	tt := []struct {
 		name     string
@@ -2249,3 +3619,2004 @@ func assertParseBoolFlags(t *testing.T, name string, got, want bool) {
 		t.Errorf("Parse(): %s: got = %v, want = %v", name, got, want)
 	}
 }
+
+func TestParser_Parse_with_default(t *testing.T) {
+	tt := []struct {
+		name string
+		args []string
+		want interface{}
+	}{
+		{
+			name: "unset",
+			args: nil,
+			want: []interface{}{true, 8080, "localhost", 1.5, 3 * time.Second},
+		},
+		{
+			name: "override",
+			args: []string{"--verbose=false", "--port=0", "--host", "", "--ratio", "0", "--timeout", "0s"},
+			want: []interface{}{false, 0, "", 0.0, time.Duration(0)},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			verbose := Bool(&register, "verbose", WithDefaultBool(true))
+			port := Int(&register, "port", WithDefaultInt(8080))
+			host := String(&register, "host", WithDefaultString("localhost"))
+			ratio := Float64(&register, "ratio", WithDefaultFloat64(1.5))
+			timeout := Duration(&register, "timeout", WithDefaultDuration(3*time.Second))
+
+			if err := parser.Parse(nil, &register, tc.args); err != nil {
+				t.Fatalf("Parse(%v): failed to parse args: %s", tc.args, err)
+			}
+
+			got := []interface{}{*verbose, *port, *host, *ratio, *timeout}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Parse(%v): got = %v, want = %v", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWithDefault_saved(t *testing.T) {
+	var register DefaultRegister
+
+	_ = Int(&register, "port", WithDefault("8080"))
+
+	flag, _ := register.LongFlag("port")
+	if v, empty := flag.Default(); v != "8080" || empty {
+		t.Errorf("Default(): got = (%q, %v), want = (%q, %v)", v, empty, "8080", false)
+	}
+}
+
+func TestWithDefault_broken_value(t *testing.T) {
+	var (
+		register DefaultRegister
+		p        int
+	)
+
+	err := IntVar(&register, &p, "port", WithDefault("eighty"))
+
+	want := &FlagError{Long: "port", Err: &ParseValueError{Type: "int", Err: ErrSyntax}}
+	if !errors.Is(err, want) {
+		t.Fatalf("IntVar(): got error = %v, want error = %v", err, want)
+	}
+}
+
+func TestWithDefault_broken_value_pointer_helper(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Int(&register, "port", WithDefault("abc"))
+
+	want := &FlagError{Long: "port", Err: &ParseValueError{Type: "int", Err: ErrSyntax}}
+	if err := register.Err(); !errors.Is(err, want) {
+		t.Errorf("Err(): got error = %v, want error = %v", err, want)
+	}
+
+	if _, ok := register.LongFlag("port"); ok {
+		t.Errorf("LongFlag(): the flag with a broken default must not be registered")
+	}
+
+	if err := parser.Parse(nil, &register, nil); !errors.Is(err, want) {
+		t.Errorf("Parse(): got error = %v, want error = %v", err, want)
+	}
+}
+
+func TestParser_Parse_with_env(t *testing.T) {
+	defer setenv(t, "NICE_TEST_PORT", "9090")()
+
+	tt := []struct {
+		name   string
+		parser DefaultParser
+		args   []string
+		want   int
+	}{
+		{
+			name: "env",
+			want: 9090,
+		},
+		{
+			name: "arg overrides env",
+			args: []string{"--port", "7070"},
+			want: 7070,
+		},
+		{
+			name:   "disable env",
+			parser: DefaultParser{DisableEnv: true},
+			want:   8080,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var register DefaultRegister
+
+			port := Int(&register, "port", WithDefaultInt(8080), WithEnv("NICE_TEST_PORT"))
+
+			if err := tc.parser.Parse(nil, &register, tc.args); err != nil {
+				t.Fatalf("Parse(%v): failed to parse args: %s", tc.args, err)
+			}
+
+			if *port != tc.want {
+				t.Errorf("Parse(%v): got = %d, want = %d", tc.args, *port, tc.want)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_with_env_broken_value(t *testing.T) {
+	defer setenv(t, "NICE_TEST_PORT", "eighty")()
+
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Int(&register, "port", WithEnv("NICE_TEST_PORT"))
+
+	err := parser.Parse(nil, &register, nil)
+
+	want := &FlagError{
+		Long: "port",
+		Err: &EnvError{
+			Name: "NICE_TEST_PORT",
+			Err:  &ParseValueError{Type: "int", Err: ErrSyntax},
+		},
+	}
+	if !errors.Is(err, want) {
+		t.Fatalf("Parse(): got error = %v, want error = %v", err, want)
+	}
+
+	if msg := err.Error(); !strings.Contains(msg, "NICE_TEST_PORT") {
+		t.Errorf("Error(): got = %q, want it to contain the variable name", msg)
+	}
+}
+
+func TestParser_Parse_broken_value_flag_name(t *testing.T) {
+	defer setenv(t, "NICE_TEST_PORT", "eighty")()
+
+	tt := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "long",
+			args: []string{"--count", "10", "--limit", "ten"},
+			want: "limit",
+		},
+		{
+			name: "short",
+			args: []string{"-n", "ten"},
+			want: "n",
+		},
+		{
+			name: "env",
+			args: []string{"--count", "10"},
+			want: "port",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			_ = Int(&register, "count")
+			_ = Int(&register, "limit")
+			_ = Int(&register, "n")
+			_ = Int(&register, "port", WithEnv("NICE_TEST_PORT"))
+
+			err := parser.Parse(nil, &register, tc.args)
+
+			var flagErr *FlagError
+			if !errors.As(err, &flagErr) {
+				t.Fatalf("Parse(%v): got error = %v, want FlagError", tc.args, err)
+			}
+
+			// Is doesn't match the flag name.
+			if want := (&ParseValueError{Type: "int", Err: ErrSyntax}); !errors.Is(flagErr.Err, want) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, flagErr.Err, want)
+			}
+
+			var pe *ParseValueError
+			if !errors.As(flagErr.Err, &pe) {
+				t.Fatalf("Parse(%v): got error = %v, want ParseValueError", tc.args, flagErr.Err)
+			}
+
+			if pe.Flag != tc.want {
+				t.Errorf("Parse(%v): Flag: got = %q, want = %q", tc.args, pe.Flag, tc.want)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_all_errors(t *testing.T) {
+	defer setenv(t, "NICE_TEST_PORT", "eighty")()
+
+	var (
+		register DefaultRegister
+		parser   = DefaultParser{AllErrors: true}
+	)
+
+	_ = Int(&register, "count")
+	_ = Bool(&register, "b")
+	_ = Duration(&register, "timeout")
+	_ = Int(&register, "port", WithEnv("NICE_TEST_PORT"), Required)
+	_ = String(&register, "name", Required)
+	_ = IntArg(&register, "n")
+
+	args := []string{"--count", "ten", "-b=maybe", "--timeout", "1 hour", "1"}
+
+	err := parser.Parse(nil, &register, args)
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Parse(%v): got error = %v, want Errors", args, err)
+	}
+
+	want := []error{
+		&FlagError{Long: "count", Err: &ParseValueError{Type: "int", Err: ErrSyntax}},
+		&FlagError{Short: "b", Err: &ParseValueError{Type: "bool", Err: ErrSyntax}},
+		&FlagError{Long: "timeout", Err: &ParseValueError{Type: "time.Duration", Err: ErrSyntax}},
+		&FlagError{Long: "port", Err: &EnvError{Name: "NICE_TEST_PORT", Err: &ParseValueError{Type: "int", Err: ErrSyntax}}},
+		&FlagError{Long: "name", Err: ErrNotProvided},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Parse(%v): got %d errors = %v, want %d errors", args, len(errs), errs, len(want))
+	}
+
+	for i := range want {
+		if !errors.Is(errs[i], want[i]) {
+			t.Errorf("Parse(%v): error #%d: got = %v, want = %v", args, i, errs[i], want[i])
+		}
+
+		if !errors.Is(err, want[i]) {
+			t.Errorf("Is(%v): expected errors will be matched", want[i])
+		}
+	}
+
+	if got, want := strings.Count(err.Error(), "\n"), len(want)-1; got != want {
+		t.Errorf("Error(): got %d lines, want %d lines: %q", got+1, want+1, err.Error())
+	}
+}
+
+func TestParser_Parse_all_errors_syntax(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   = DefaultParser{AllErrors: true}
+	)
+
+	_ = Int(&register, "count")
+
+	args := []string{"--count", "ten", "--unknown"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &ParseFlagError{Name: "--unknown", Err: ErrUnknown}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, got, want)
+	}
+
+	if _, ok := got.(Errors); ok {
+		t.Errorf("Parse(%v): got Errors, want the unknown flag error only", args)
+	}
+}
+
+func TestParser_Parse_stop_on_first_non_flag(t *testing.T) {
+	tt := []struct {
+		name        string
+		args        []string
+		wantVerbose bool
+		wantRest    []string
+	}{
+		{
+			name:        "flags after the arg",
+			args:        []string{"-v", "run", "--verbose", "-x", "--", "file"},
+			wantVerbose: true,
+			wantRest:    []string{"--verbose", "-x", "--", "file"},
+		},
+		{
+			name:     "number",
+			args:     []string{"-1", "-v"},
+			wantRest: []string{"-v"},
+		},
+		{
+			name:        "no args",
+			args:        []string{"-v"},
+			wantVerbose: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   = DefaultParser{StopOnFirstNonFlag: true}
+			)
+
+			verbose := Bool(&register, "verbose", WithShort("v"))
+			_ = StringArg(&register, "cmd", Optional)
+			_ = RestStrings(&register, "rest")
+
+			if err := parser.Parse(nil, &register, tc.args); err != nil {
+				t.Fatalf("Parse(%v): failed to parse args: %s", tc.args, err)
+			}
+
+			if *verbose != tc.wantVerbose {
+				t.Errorf("Parse(%v): verbose: got = %v, want = %v", tc.args, *verbose, tc.wantVerbose)
+			}
+
+			if got := parser.RestArgs(); !reflect.DeepEqual(got, tc.wantRest) && (len(got) != 0 || len(tc.wantRest) != 0) {
+				t.Errorf("RestArgs(): got = %#v, want = %#v", got, tc.wantRest)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_passthrough(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   = DefaultParser{Passthrough: true}
+	)
+
+	verbose := Bool(&register, "verbose", WithShort("v"))
+	cmd := StringArg(&register, "cmd")
+	rest := RestStrings(&register, "rest")
+
+	args := []string{"exec", "-v", "a", "--", "ls", "-la", "--", "b"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if !*verbose {
+		t.Errorf("Parse(%v): verbose: got = %v, want = %v", args, *verbose, true)
+	}
+
+	if *cmd != "exec" {
+		t.Errorf("Parse(%v): cmd: got = %q, want = %q", args, *cmd, "exec")
+	}
+
+	if want := []string{"a"}; !reflect.DeepEqual(*rest, want) {
+		t.Errorf("Parse(%v): rest: got = %#v, want = %#v", args, *rest, want)
+	}
+
+	if want := []string{"a"}; !reflect.DeepEqual(parser.RestArgs(), want) {
+		t.Errorf("RestArgs(): got = %#v, want = %#v", parser.RestArgs(), want)
+	}
+
+	if want := []string{"ls", "-la", "--", "b"}; !reflect.DeepEqual(parser.PassthroughArgs(), want) {
+		t.Errorf("PassthroughArgs(): got = %#v, want = %#v", parser.PassthroughArgs(), want)
+	}
+}
+
+func TestParser_Parse_prefix_match(t *testing.T) {
+	tt := []struct {
+		name        string
+		args        []string
+		wantVerbose bool
+		wantErr     error
+	}{
+		{
+			name:        "unambiguous",
+			args:        []string{"--verb"},
+			wantVerbose: true,
+		},
+		{
+			name:        "exact",
+			args:        []string{"--verbose"},
+			wantVerbose: true,
+		},
+		{
+			name:    "ambiguous",
+			args:    []string{"--ver"},
+			wantErr: &AmbiguousFlagError{Name: "--ver", Candidates: []string{"--verbose", "--version"}},
+		},
+		{
+			name:    "no match",
+			args:    []string{"--quiet"},
+			wantErr: &ParseFlagError{Name: "--quiet", Err: ErrUnknown},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   = DefaultParser{PrefixMatch: true}
+			)
+
+			verbose := Bool(&register, "verbose")
+			_ = Bool(&register, "version")
+
+			err := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+
+			if *verbose != tc.wantVerbose {
+				t.Errorf("Parse(%v): verbose: got = %v, want = %v", tc.args, *verbose, tc.wantVerbose)
+			}
+
+			if want, ok := tc.wantErr.(*AmbiguousFlagError); ok {
+				var got *AmbiguousFlagError
+				if !errors.As(err, &got) || !reflect.DeepEqual(got.Candidates, want.Candidates) {
+					t.Errorf("Parse(%v): got error = %#v, want error = %#v", tc.args, err, want)
+				}
+
+				if !errors.Is(err, ErrSyntax) {
+					t.Errorf("Is(%v): expected errors will be matched", ErrSyntax)
+				}
+			}
+		})
+	}
+}
+
+func TestParser_Parse_prefix_match_ambiguous_candidates(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   = DefaultParser{PrefixMatch: true}
+	)
+
+	_ = Bool(&register, "verbose")
+	_ = Bool(&register, "version")
+	_ = Bool(&register, "vendor")
+
+	args := []string{"--ve"}
+
+	err := parser.Parse(nil, &register, args)
+
+	var got *AmbiguousFlagError
+	if !errors.As(err, &got) {
+		t.Fatalf("Parse(%v): got error = %v, want ambiguous flag error", args, err)
+	}
+
+	if want := []string{"--verbose", "--version", "--vendor"}; !reflect.DeepEqual(got.Candidates, want) {
+		t.Errorf("Parse(%v): candidates: got = %v, want = %v", args, got.Candidates, want)
+	}
+
+	const wantMsg = "cli: ambiguous flag '--ve': matches --verbose, --version, --vendor"
+	if got.Error() != wantMsg {
+		t.Errorf("Error(): got = %q, want = %q", got.Error(), wantMsg)
+	}
+
+	if !errors.Is(err, ErrSyntax) {
+		t.Errorf("Is(%v): expected errors will be matched", ErrSyntax)
+	}
+}
+
+func TestParser_Parse_prefix_match_universal(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   = DefaultParser{PrefixMatch: true, Universal: true}
+	)
+
+	_ = Bool(&register, "verbose")
+	_ = Bool(&register, "version")
+
+	args := []string{"-ver"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &AmbiguousFlagError{Name: "-ver", Candidates: []string{"-verbose", "-version"}}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, got, want)
+	}
+
+	const wantMsg = "cli: ambiguous flag '-ver': matches -verbose, -version"
+	if got.Error() != wantMsg {
+		t.Errorf("Error(): got = %q, want = %q", got.Error(), wantMsg)
+	}
+}
+
+func TestParser_Parse_prefix_match_hidden(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   = DefaultParser{PrefixMatch: true}
+	)
+
+	verbose := Bool(&register, "verbose")
+	version := Bool(&register, "version", WithHidden())
+
+	args := []string{"--ver"}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if !*verbose || *version {
+		t.Errorf("Parse(%v): got verbose = %v, version = %v, want verbose = %v, version = %v",
+			args, *verbose, *version, true, false)
+	}
+
+	// Hidden flags still work by the full name.
+	args = []string{"--version"}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if !*version {
+		t.Errorf("Parse(%v): got version = %v, want = %v", args, *version, true)
+	}
+}
+
+func TestParser_Parse_prefix_match_disabled(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Bool(&register, "verbose")
+
+	args := []string{"--verb"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &ParseFlagError{Name: "--verb", Err: ErrUnknown}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, got, want)
+	}
+}
+
+func TestParser_Parse_normalize_func(t *testing.T) {
+	tt := []struct {
+		name      string
+		args      []string
+		wantLevel string
+		wantQuiet bool
+	}{
+		{
+			name:      "normalized",
+			args:      []string{"--log_level", "debug"},
+			wantLevel: "debug",
+			wantQuiet: true,
+		},
+		{
+			name:      "original",
+			args:      []string{"--log-level=info"},
+			wantLevel: "info",
+			wantQuiet: true,
+		},
+		{
+			name:      "negation",
+			args:      []string{"--no-be_quiet"},
+			wantQuiet: false,
+		},
+		{
+			name:      "prefix",
+			args:      []string{"--log_l", "warn"},
+			wantLevel: "warn",
+			wantQuiet: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var register DefaultRegister
+
+			parser := DefaultParser{
+				EnableNegation: true,
+				PrefixMatch:    true,
+				NormalizeFunc: func(name string) string {
+					return strings.ReplaceAll(name, "_", "-")
+				},
+			}
+
+			level := String(&register, "log-level")
+			quiet := Bool(&register, "be-quiet", WithDefaultBool(true))
+
+			if err := parser.Parse(nil, &register, tc.args); err != nil {
+				t.Fatalf("Parse(%v): failed to parse args: %s", tc.args, err)
+			}
+
+			if *level != tc.wantLevel {
+				t.Errorf("Parse(%v): log-level: got = %q, want = %q", tc.args, *level, tc.wantLevel)
+			}
+
+			if *quiet != tc.wantQuiet {
+				t.Errorf("Parse(%v): be-quiet: got = %v, want = %v", tc.args, *quiet, tc.wantQuiet)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_case_insensitive(t *testing.T) {
+	tt := []struct {
+		name        string
+		args        []string
+		wantVerbose bool
+		wantAll     bool
+		wantArch    bool
+		wantErr     error
+	}{
+		{name: "title", args: []string{"--Verbose"}, wantVerbose: true},
+		{name: "upper", args: []string{"--VERBOSE"}, wantVerbose: true},
+		{name: "lower", args: []string{"--verbose"}, wantVerbose: true},
+		{name: "negation", args: []string{"--verbose", "--No-Verbose"}, wantVerbose: false},
+		{name: "short lower", args: []string{"-a"}, wantAll: true},
+		{name: "short upper", args: []string{"-A"}, wantArch: true},
+		{
+			name:    "unknown short",
+			args:    []string{"-V"},
+			wantErr: &ParseFlagError{Name: "-V", Err: ErrUnknown},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   = DefaultParser{CaseInsensitive: true, EnableNegation: true}
+			)
+
+			verbose := Bool(&register, "verbose", WithShort("v"))
+			all := Bool(&register, "a")
+			arch := Bool(&register, "A")
+
+			err := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+
+			if *verbose != tc.wantVerbose || *all != tc.wantAll || *arch != tc.wantArch {
+				t.Errorf("Parse(%v): got = (%v, %v, %v), want = (%v, %v, %v)",
+					tc.args, *verbose, *all, *arch, tc.wantVerbose, tc.wantAll, tc.wantArch)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_case_insensitive_duplicate(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   = DefaultParser{CaseInsensitive: true}
+	)
+
+	_ = Bool(&register, "Foo")
+	_ = Bool(&register, "foo")
+
+	args := []string{"--foo"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &FlagError{Long: "foo", Err: ErrDuplicate}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, got, want)
+	}
+
+	// Both flags are fine in the case-sensitive mode.
+	parser.CaseInsensitive = false
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+}
+
+func TestParser_Parse_case_sensitive(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Bool(&register, "verbose")
+
+	args := []string{"--Verbose"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &ParseFlagError{Name: "--Verbose", Err: ErrUnknown}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, got, want)
+	}
+}
+
+func TestParser_Parse_hidden(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	verbose := Bool(&register, "verbose")
+	internals := Bool(&register, "debug-internals", WithHidden())
+	secret := StringArg(&register, "secret", WithHiddenArg())
+
+	args := []string{"--debug-internals", "--verbose", "s3cr3t"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if !*verbose || !*internals || *secret != "s3cr3t" {
+		t.Errorf("Parse(%v): got = (%v, %v, %q), want = (%v, %v, %q)",
+			args, *verbose, *internals, *secret, true, true, "s3cr3t")
+	}
+
+	if got := len(register.Flags()); got != 2 {
+		t.Errorf("Flags(): got %d flags, want %d", got, 2)
+	}
+
+	visible := register.VisibleFlags()
+	if len(visible) != 1 || visible[0].Long != "verbose" {
+		t.Errorf("VisibleFlags(): got = %v, want only the verbose flag", visible)
+	}
+}
+
+func TestParser_Parse_with_annotation(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = String(&register, "config",
+		WithAnnotation("completion", "files"),
+		WithAnnotation("man", "FILES"),
+		WithAnnotation("required", "true"),
+	)
+	_ = Bool(&register, "v")
+
+	// The "required" annotation is only metadata.
+	if err := parser.Parse(nil, &register, nil); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	flag, _ := register.LongFlag("config")
+
+	want := map[string]string{"completion": "files", "man": "FILES", "required": "true"}
+	if !reflect.DeepEqual(flag.Annotations, want) {
+		t.Errorf("Annotations: got = %v, want = %v", flag.Annotations, want)
+	}
+
+	if got, ok := flag.Annotation("completion"); !ok || got != "files" {
+		t.Errorf("Annotation(%q): got = (%q, %v), want = (%q, %v)", "completion", got, ok, "files", true)
+	}
+
+	if got, ok := flag.Annotation("unknown"); ok || got != "" {
+		t.Errorf("Annotation(%q): got = (%q, %v), want = (%q, %v)", "unknown", got, ok, "", false)
+	}
+
+	short, _ := register.ShortFlag("v")
+	if _, ok := short.Annotation("completion"); ok || short.Annotations != nil {
+		t.Errorf("Annotations: got = %v, want = nil", short.Annotations)
+	}
+}
+
+func TestRegister_nil_target(t *testing.T) {
+	tt := []struct {
+		name     string
+		register func(r Register) error
+		want     error
+	}{
+		{
+			name:     "BoolVar",
+			register: func(r Register) error { return BoolVar(r, nil, "flag") },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "IntVar",
+			register: func(r Register) error { return IntVar(r, nil, "f", WithDefault("1")) },
+			want:     &FlagError{Short: "f", Err: ErrNilTarget},
+		},
+		{
+			name:     "StringVar",
+			register: func(r Register) error { return StringVar(r, nil, "flag") },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "Float64Var",
+			register: func(r Register) error { return Float64Var(r, nil, "flag") },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "UintVar",
+			register: func(r Register) error { return UintVar(r, nil, "flag") },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "DurationVar",
+			register: func(r Register) error { return DurationVar(r, nil, "flag") },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "URLVar",
+			register: func(r Register) error { return URLVar(r, nil, "flag") },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "StrictURLVar",
+			register: func(r Register) error { return StrictURLVar(r, nil, "flag") },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "RegexpVar",
+			register: func(r Register) error { return RegexpVar(r, nil, "flag") },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "RegexpPOSIXVar",
+			register: func(r Register) error { return RegexpPOSIXVar(r, nil, "flag") },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "TimeVar",
+			register: func(r Register) error { return TimeVar(r, nil, "flag", DateFlag) },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "StringSliceVar",
+			register: func(r Register) error { return StringSliceVar(r, nil, "flag") },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "Var",
+			register: func(r Register) error { return Var(r, nil, "flag") },
+			want:     &FlagError{Long: "flag", Err: ErrNilTarget},
+		},
+		{
+			name:     "IntArgVar",
+			register: func(r Register) error { return IntArgVar(r, nil, "arg") },
+			want:     &ArgError{Name: "arg", Err: ErrNilTarget},
+		},
+		{
+			name:     "URLArgVar",
+			register: func(r Register) error { return URLArgVar(r, nil, "arg") },
+			want:     &ArgError{Name: "arg", Err: ErrNilTarget},
+		},
+		{
+			name:     "StrictURLArgVar",
+			register: func(r Register) error { return StrictURLArgVar(r, nil, "arg") },
+			want:     &ArgError{Name: "arg", Err: ErrNilTarget},
+		},
+		{
+			name:     "RegexpArgVar",
+			register: func(r Register) error { return RegexpArgVar(r, nil, "arg") },
+			want:     &ArgError{Name: "arg", Err: ErrNilTarget},
+		},
+		{
+			name:     "RegexpPOSIXArgVar",
+			register: func(r Register) error { return RegexpPOSIXArgVar(r, nil, "arg") },
+			want:     &ArgError{Name: "arg", Err: ErrNilTarget},
+		},
+		{
+			name:     "TimeArgVar",
+			register: func(r Register) error { return TimeArgVar(r, nil, "arg", DateFlag) },
+			want:     &ArgError{Name: "arg", Err: ErrNilTarget},
+		},
+		{
+			name:     "StringSliceArgVar",
+			register: func(r Register) error { return StringSliceArgVar(r, nil, "arg") },
+			want:     &ArgError{Name: "arg", Err: ErrNilTarget},
+		},
+		{
+			name:     "RestIntsVar",
+			register: func(r Register) error { return RestIntsVar(r, nil, "rest") },
+			want:     &RestArgsError{Name: "rest", Err: ErrNilTarget},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var register DefaultRegister
+
+			if err := tc.register(&register); !errors.Is(err, tc.want) {
+				t.Fatalf("%s(): got error = %v, want error = %v", tc.name, err, tc.want)
+			}
+
+			if err := register.Err(); !errors.Is(err, tc.want) {
+				t.Errorf("Err(): got error = %v, want error = %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegisterFlag_nil_bool_target(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	want := &FlagError{Long: "verbose", Err: ErrNilTarget}
+
+	got := register.RegisterFlag(Flag{Value: newBoolValue((*bool)(nil)), Long: "verbose"})
+	if !errors.Is(got, want) {
+		t.Fatalf("RegisterFlag(): got error = %v, want error = %v", got, want)
+	}
+
+	if !errors.Is(got, ErrNilTarget) {
+		t.Errorf("Is(%v): expected errors will be matched", ErrNilTarget)
+	}
+
+	if _, ok := register.LongFlag("verbose"); ok {
+		t.Errorf("LongFlag(): the flag with nil target must not be registered")
+	}
+
+	// The parse reports the registration error instead of the nil-pointer
+	// panic in the Set.
+	args := []string{"--verbose"}
+	if err := parser.Parse(nil, &register, args); !errors.Is(err, want) {
+		t.Errorf("Parse(%v): got error = %v, want error = %v", args, err, want)
+	}
+}
+
+func TestNewDefaultParser(t *testing.T) {
+	tt := []struct {
+		name    string
+		options []ParserOptionApplyer
+		want    DefaultParser
+	}{
+		{
+			name: "zero",
+			want: DefaultParser{},
+		},
+		{
+			name: "all",
+			options: []ParserOptionApplyer{
+				WithUniversal(),
+				WithIgnoreUnknownFlags(),
+				WithIgnoreUnknownArgs(),
+				WithDisablePosixStyle(),
+				WithDisableInlineValue(),
+				WithOverrideFlags(),
+				WithOverrideArgs(),
+				WithErrorHandling(PanicOnError),
+			},
+			want: DefaultParser{
+				Universal:          true,
+				IgnoreUnknownFlags: true,
+				IgnoreUnknownArgs:  true,
+				DisablePosixStyle:  true,
+				DisableInlineValue: true,
+				OverrideFlags:      true,
+				OverrideArgs:       true,
+				ErrorHandling:      PanicOnError,
+			},
+		},
+		{
+			name:    "in order",
+			options: []ParserOptionApplyer{WithIgnoreUnknownFlags(), nil, StrictFlags()},
+			want:    DefaultParser{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NewDefaultParser(tc.options...)
+			if !reflect.DeepEqual(*got, tc.want) {
+				t.Errorf("NewDefaultParser(): got = %+v, want = %+v", *got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_deprecated_flag(t *testing.T) {
+	var (
+		register DefaultRegister
+		output   strings.Builder
+	)
+
+	parser := DefaultParser{DeprecationOutput: &output}
+
+	old := Int(&register, "old", WithDeprecated("use --new instead"))
+	v := Bool(&register, "v", WithDeprecated("use --verbose instead"))
+	_ = Int(&register, "new")
+
+	args := []string{"--old", "1", "-v", "--old", "2", "--new", "3"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if *old != 2 || !*v {
+		t.Errorf("Parse(%v): got = (%d, %v), want = (%d, %v)", args, *old, *v, 2, true)
+	}
+
+	want := "flag --old is deprecated: use --new instead\n" +
+		"flag -v is deprecated: use --verbose instead\n"
+	if got := output.String(); got != want {
+		t.Errorf("Parse(%v): got output = %q, want output = %q", args, got, want)
+	}
+}
+
+func TestParser_SetOutput(t *testing.T) {
+	defer func(exit func(int)) { osExit = exit }(osExit)
+
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	var (
+		register DefaultRegister
+		output   bytes.Buffer
+	)
+
+	parser := DefaultParser{ErrorHandling: ExitOnError}
+	parser.SetOutput(&output)
+
+	_ = Int(&register, "old", WithDeprecated("use --new instead"))
+
+	args := []string{"--old", "1", "--unknown"}
+	_ = parser.Parse(nil, &register, args)
+
+	want := "flag --old is deprecated: use --new instead\n" +
+		"cli: parse flag error: '--unknown': unknown\n"
+	if got := output.String(); got != want {
+		t.Errorf("Parse(%v): got output = %q, want output = %q", args, got, want)
+	}
+
+	if exitCode != 2 {
+		t.Errorf("Parse(%v): got exit code = %v, want = %v", args, exitCode, 2)
+	}
+
+	if got := parser.Output(); got != &output {
+		t.Errorf("Output(): got = %v, want = %v", got, &output)
+	}
+
+	parser.SetOutput(nil)
+
+	if got := parser.Output(); got != os.Stderr {
+		t.Errorf("Output(): got = %v, want = os.Stderr", got)
+	}
+}
+
+func TestParser_Parse_negation(t *testing.T) {
+	tt := []struct {
+		name    string
+		args    []string
+		want    []interface{}
+		wantErr error
+	}{
+		{
+			name: "negated",
+			args: []string{"--verbose", "--no-verbose", "arg"},
+			want: []interface{}{false, false, "arg"},
+		},
+		{
+			name: "negation before flag",
+			args: []string{"--no-verbose", "--verbose"},
+			want: []interface{}{true, false, ""},
+		},
+		{
+			name: "bool-like arg is not consumed",
+			args: []string{"--verbose", "--no-verbose", "true"},
+			want: []interface{}{false, false, "true"},
+		},
+		{
+			name:    "value",
+			args:    []string{"--no-verbose=true"},
+			want:    []interface{}{false, false, ""},
+			wantErr: &ParseFlagError{Name: "--no-verbose", Err: ErrSyntax},
+		},
+		{
+			name: "already negative flag",
+			args: []string{"--no-cache"},
+			want: []interface{}{false, true, ""},
+		},
+		{
+			name:    "double negation",
+			args:    []string{"--no-no-cache"},
+			want:    []interface{}{false, false, ""},
+			wantErr: &ParseFlagError{Name: "--no-no-cache", Err: ErrUnknown},
+		},
+		{
+			name:    "non-bool flag",
+			args:    []string{"--no-name"},
+			want:    []interface{}{false, false, ""},
+			wantErr: &ParseFlagError{Name: "--no-name", Err: ErrUnknown},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var register DefaultRegister
+
+			parser := DefaultParser{EnableNegation: true}
+
+			verbose := Bool(&register, "verbose")
+			noCache := Bool(&register, "no-cache")
+			_ = String(&register, "name")
+			arg := StringArg(&register, "arg", WithOptional())
+
+			err := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+
+			got := []interface{}{*verbose, *noCache, *arg}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Parse(%v): got = %v, want = %v", tc.args, got, tc.want)
+			}
+
+			// Negated flags are not registered.
+			if n := len(register.Flags()); n != 3 {
+				t.Errorf("Flags(): got %d flags, want %d", n, 3)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_negation_disabled(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Bool(&register, "verbose")
+
+	args := []string{"--no-verbose"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &ParseFlagError{Name: "--no-verbose", Err: ErrUnknown}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, got, want)
+	}
+}
+
+func TestParser_Parse_typed_rest_args(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = StringArg(&register, "name")
+	_ = IntRestArgs(&register, "ints")
+
+	args := []string{"test", "1", "2", "three"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &ArgError{
+		Name:  "ints",
+		Index: 3,
+		Err:   &ParseValueError{Type: "int", Err: ErrSyntax},
+	}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %q, want error = %q", args, got, want)
+	}
+}
+
+func TestParser_Parse_typed_rest_args_float64_duration(t *testing.T) {
+	var (
+		parser DefaultParser
+
+		floatsRegister    DefaultRegister
+		durationsRegister DefaultRegister
+	)
+
+	floats := Float64RestArgs(&floatsRegister, "floats")
+	durations := DurationRestArgs(&durationsRegister, "durations")
+
+	if err := parser.Parse(nil, &floatsRegister, []string{"0.5", "-1"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if !reflect.DeepEqual(*floats, []float64{0.5, -1}) {
+		t.Errorf("Parse(): floats: got = %v, want = %v", *floats, []float64{0.5, -1})
+	}
+
+	if err := parser.Parse(nil, &durationsRegister, []string{"1s", "2m"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if want := []time.Duration{time.Second, 2 * time.Minute}; !reflect.DeepEqual(*durations, want) {
+		t.Errorf("Parse(): durations: got = %v, want = %v", *durations, want)
+	}
+}
+
+func TestParser_Parse_rest_bounds(t *testing.T) {
+	tt := []struct {
+		name string
+		args []string
+		want error
+	}{
+		{
+			name: "not enough",
+			args: []string{"a"},
+			want: &RestArgsError{Name: "files", Err: ErrNotEnough},
+		},
+		{
+			name: "min",
+			args: []string{"a", "b"},
+		},
+		{
+			name: "max",
+			args: []string{"a", "b", "c"},
+		},
+		{
+			name: "too many",
+			args: []string{"a", "b", "c", "d"},
+			want: &RestArgsError{Name: "files", Err: ErrTooMany},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			_ = RestStrings(&register, "files", WithMinRest(2), WithMaxRest(3))
+
+			got := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(got, tc.want) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_rest_single_bound(t *testing.T) {
+	tt := []struct {
+		name    string
+		options []RestOptionApplyer
+		args    []string
+		want    error
+	}{
+		{
+			name:    "min",
+			options: []RestOptionApplyer{WithMinRest(2)},
+			args:    []string{"a"},
+			want:    ErrNotEnough,
+		},
+		{
+			name:    "max",
+			options: []RestOptionApplyer{WithMaxRest(3)},
+			args:    []string{"a", "b", "c", "d"},
+			want:    ErrTooMany,
+		},
+		{
+			name:    "unlimited max",
+			options: []RestOptionApplyer{WithMinRest(2)},
+			args:    []string{"a", "b", "c", "d"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			_ = RestStrings(&register, "files", tc.options...)
+
+			got := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(got, tc.want) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, got, tc.want)
+			}
+
+			var restErr *RestArgsError
+			if tc.want != nil && (!errors.As(got, &restErr) || restErr.Name != "files") {
+				t.Errorf("Parse(%v): got error = %#v, want rest args error", tc.args, got)
+			}
+		})
+	}
+}
+
+func TestParser_Clone(t *testing.T) {
+	var register DefaultRegister
+	parser := DefaultParser{
+		IgnoreUnknownFlags: true,
+		IgnoreUnknownArgs:  true,
+	}
+
+	a := Int(&register, "a")
+	_ = Bool(&register, "verbose")
+
+	if err := MutuallyExclusive(&parser, "a", "verbose"); err != nil {
+		t.Fatalf("MutuallyExclusive(): failed to add constraint: %s", err)
+	}
+
+	if err := parser.Parse(nil, &register, []string{"-a", "1"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	cloneRegister := register.Clone()
+	clone := parser.Clone()
+
+	for _, flag := range cloneRegister.Flags() {
+		if flag.Set() {
+			t.Errorf("Clone(): flag %q must not be set", flag.String())
+		}
+	}
+
+	c := Int(cloneRegister, "c")
+
+	if err := AtLeastOne(clone, "a", "c"); err != nil {
+		t.Fatalf("AtLeastOne(): failed to add constraint: %s", err)
+	}
+
+	if got, want := len(register.Flags()), 2; got != want {
+		t.Errorf("Clone(): original flags: got = %v, want = %v", got, want)
+	}
+
+	if got, want := len(cloneRegister.Flags()), 3; got != want {
+		t.Errorf("Clone(): clone flags: got = %v, want = %v", got, want)
+	}
+
+	// Original parser doesn't know about -c.
+	args := []string{"-a", "2", "-c", "3"}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if *a != 2 {
+		t.Errorf("Parse(%v): a: got = %v, want = %v", args, *a, 2)
+	}
+
+	if *c != 0 {
+		t.Errorf("Parse(%v): c: got = %v, want = %v", args, *c, 0)
+	}
+
+	if got, want := parser.Unknown(), []string{"-c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unknown(): got = %v, want = %v", got, want)
+	}
+
+	// Clone has modes and constraints of the original plus its own ones.
+	args = []string{"-c", "3", "--unknown"}
+	if err := clone.Parse(nil, cloneRegister, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if *c != 3 {
+		t.Errorf("Parse(%v): c: got = %v, want = %v", args, *c, 3)
+	}
+
+	if got, want := clone.Unknown(), []string{"--unknown"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unknown(): got = %v, want = %v", got, want)
+	}
+
+	if got, want := len(parser.constraints), 1; got != want {
+		t.Errorf("Clone(): original constraints: got = %v, want = %v", got, want)
+	}
+
+	// Constraints of the original are copied.
+	other := parser.Clone()
+	otherRegister := register.Clone()
+
+	args = []string{"-a", "1", "--verbose"}
+	var conflict *ConflictError
+	if err := other.Parse(nil, otherRegister, args); !errors.As(err, &conflict) {
+		t.Errorf("Parse(%v): got error = %v, want conflict error", args, err)
+	}
+
+	// Values are shared between the original and the clone.
+	if *a != 1 {
+		t.Errorf("Parse(%v): a: got = %v, want = %v", args, *a, 1)
+	}
+}
+
+func TestParser_SubParser(t *testing.T) {
+	var register DefaultRegister
+	var parser DefaultParser
+
+	verbose := Bool(&register, "verbose", WithPersistent())
+	dry := Bool(&register, "dry")
+
+	args := []string{"--dry"}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	sub := parser.SubParser()
+
+	var subRegister DefaultRegister
+	force := Bool(&subRegister, "force")
+
+	args = []string{"--verbose", "--force"}
+	if err := sub.Parse(nil, &subRegister, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if !*verbose {
+		t.Errorf("Parse(%v): verbose: got = %v, want = %v", args, *verbose, true)
+	}
+
+	if !*force {
+		t.Errorf("Parse(%v): force: got = %v, want = %v", args, *force, true)
+	}
+
+	if flag, ok := subRegister.LongFlag("verbose"); !ok || !flag.Set() {
+		t.Errorf("LongFlag(%q): got = %v, %v, want set flag", "verbose", flag, ok)
+	}
+
+	if flag, _ := register.LongFlag("verbose"); flag.Set() {
+		t.Errorf("LongFlag(%q): parent flag must not be set", "verbose")
+	}
+
+	// Non-persistent flags are not inherited.
+	args = []string{"--dry"}
+	if err := sub.Parse(nil, &subRegister, args); !errors.Is(err, &ParseFlagError{Name: "--dry", Err: ErrUnknown}) {
+		t.Errorf("Parse(%v): got error = %v, want unknown flag error", args, err)
+	}
+
+	if !*dry {
+		t.Errorf("Parse(%v): dry: got = %v, want = %v", args, *dry, true)
+	}
+
+	// Inherited flags are passed down the tree.
+	subsub := sub.SubParser()
+
+	var subsubRegister DefaultRegister
+	if err := subsub.Parse(nil, &subsubRegister, []string{"--verbose"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if got, want := len(subsubRegister.Flags()), 1; got != want {
+		t.Errorf("Flags(): got = %v, want = %v", got, want)
+	}
+}
+
+// Tests use zero parsers and rely on ContinueOnError being the default.
+func TestParser_ErrorHandling_default(t *testing.T) {
+	var parser DefaultParser
+	if parser.ErrorHandling != ContinueOnError {
+		t.Errorf("DefaultParser{}: got ErrorHandling = %v, want = %v", parser.ErrorHandling, ContinueOnError)
+	}
+
+	if got := NewDefaultParser().ErrorHandling; got != ContinueOnError {
+		t.Errorf("NewDefaultParser(): got ErrorHandling = %v, want = %v", got, ContinueOnError)
+	}
+}
+
+func TestParser_Parse_error_handling(t *testing.T) {
+	defer func(exit func(int)) { osExit = exit }(osExit)
+
+	var exitCode int
+	osExit = func(code int) { exitCode = code }
+
+	tt := []struct {
+		name      string
+		handling  ErrorHandling
+		args      []string
+		wantErr   error
+		wantPanic bool
+		wantExit  int
+	}{
+		{
+			name:     "continue",
+			handling: ContinueOnError,
+			args:     []string{"--unknown"},
+			wantErr:  &ParseFlagError{Name: "--unknown", Err: ErrUnknown},
+			wantExit: -1,
+		},
+		{
+			name:     "continue without error",
+			handling: ContinueOnError,
+			wantExit: -1,
+		},
+		{
+			name:     "exit",
+			handling: ExitOnError,
+			args:     []string{"--unknown"},
+			wantErr:  &ParseFlagError{Name: "--unknown", Err: ErrUnknown},
+			wantExit: 2,
+		},
+		{
+			name:     "exit on help",
+			handling: ExitOnError,
+			args:     []string{"--help"},
+			wantErr:  ErrHelp,
+			wantExit: 0,
+		},
+		{
+			name:     "exit without error",
+			handling: ExitOnError,
+			wantExit: -1,
+		},
+		{
+			name:      "panic",
+			handling:  PanicOnError,
+			args:      []string{"--unknown"},
+			wantErr:   &ParseFlagError{Name: "--unknown", Err: ErrUnknown},
+			wantPanic: true,
+			wantExit:  -1,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			exitCode = -1
+
+			var register DefaultRegister
+			parser := DefaultParser{
+				ErrorHandling: tc.handling,
+				HelpFunc:      func(*DefaultParser) {},
+			}
+			parser.SetOutput(ioutil.Discard)
+
+			var (
+				err       error
+				recovered interface{}
+			)
+			func() {
+				defer func() { recovered = recover() }()
+
+				err = parser.Parse(nil, &register, tc.args)
+			}()
+
+			if tc.wantPanic {
+				panicErr, _ := recovered.(error)
+				if !errors.Is(panicErr, tc.wantErr) {
+					t.Errorf("Parse(%v): got panic = %v, want = %v", tc.args, recovered, tc.wantErr)
+				}
+			} else {
+				if recovered != nil {
+					t.Fatalf("Parse(%v): unexpected panic: %v", tc.args, recovered)
+				}
+
+				if tc.wantErr == nil && err != nil || tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+					t.Errorf("Parse(%v): got error = %v, want = %v", tc.args, err, tc.wantErr)
+				}
+			}
+
+			if exitCode != tc.wantExit {
+				t.Errorf("Parse(%v): got exit code = %v, want = %v", tc.args, exitCode, tc.wantExit)
+			}
+		})
+	}
+}
+
+func TestParser_FlagValues(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	if got := parser.FlagValues(); len(got) != 0 {
+		t.Errorf("FlagValues(): got = %v, want empty values", got)
+	}
+
+	if got := parser.ArgValues(); got != nil {
+		t.Errorf("ArgValues(): got = %v, want = nil", got)
+	}
+
+	parser.HelpFunc = func(*DefaultParser) {}
+
+	_ = String(&register, "log-level", WithDefault("info"))
+	_ = Bool(&register, "v")
+	_ = Int(&register, "port", WithShort("p"))
+	_ = StringArg(&register, "src")
+	_ = StringArg(&register, "dst", WithOptional())
+
+	args := []string{"-p", "8080", "-v", "a.txt"}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	wantFlags := map[string]string{
+		"log-level": "info",
+		"v":         "true",
+		"port":      "8080",
+	}
+	if got := parser.FlagValues(); !reflect.DeepEqual(got, wantFlags) {
+		t.Errorf("FlagValues(): got = %v, want = %v", got, wantFlags)
+	}
+
+	wantArgs := []string{"a.txt", ""}
+	if got := parser.ArgValues(); !reflect.DeepEqual(got, wantArgs) {
+		t.Errorf("ArgValues(): got = %q, want = %q", got, wantArgs)
+	}
+}
+
+func TestRegister_shorthand(t *testing.T) {
+	tt := []struct {
+		name      string
+		shorthand rune
+		wantErr   error
+	}{
+		{name: "lower", shorthand: 'v'},
+		{name: "upper", shorthand: 'V'},
+		{name: "digit", shorthand: '1'},
+		{
+			name:      "multi-byte",
+			shorthand: 'ü',
+			wantErr:   &FlagError{Short: "ü", Long: "verbose", Err: ErrInvalidName},
+		},
+		{
+			name:      "dash",
+			shorthand: '-',
+			wantErr:   &FlagError{Short: "-", Long: "verbose", Err: ErrInvalidName},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var register DefaultRegister
+
+			err := BoolVar(&register, new(bool), "verbose", WithShorthand(tc.shorthand))
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("BoolVar(): got error = %v, want = %v", err, tc.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("BoolVar(): failed to register flag: %s", err)
+			}
+
+			if _, ok := register.ShortFlag(string(tc.shorthand)); !ok {
+				t.Errorf("ShortFlag(%q): flag not found", string(tc.shorthand))
+			}
+		})
+	}
+}
+
+func TestRegister_with_long(t *testing.T) {
+	tt := []struct {
+		name     string
+		flag     string
+		options  []FlagOptionApplyer
+		wantErr  error
+		wantLong string
+	}{
+		{
+			name:     "empty name",
+			options:  []FlagOptionApplyer{WithShort("v"), WithLong("verbose")},
+			wantLong: "verbose",
+		},
+		{
+			name:     "short name",
+			flag:     "v",
+			options:  []FlagOptionApplyer{WithLong("verbose")},
+			wantLong: "verbose",
+		},
+		{
+			name:    "same name",
+			flag:    "verbose",
+			options: []FlagOptionApplyer{WithLong("verbose")},
+			wantErr: &FlagError{Long: "verbose", Err: errDuplicateLong},
+		},
+		{
+			name:    "conflict",
+			flag:    "verbose",
+			options: []FlagOptionApplyer{WithLong("debug")},
+			wantErr: &FlagError{Long: "verbose", Err: errDuplicateLong},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var register DefaultRegister
+
+			err := BoolVar(&register, new(bool), tc.flag, tc.options...)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("BoolVar(): got error = %v, want = %v", err, tc.wantErr)
+				}
+
+				if !errors.Is(err, ErrDuplicate) || !errors.Is(err, ErrInvalidName) {
+					t.Errorf("Is(%v, %v): expected errors will be matched", ErrDuplicate, ErrInvalidName)
+				}
+
+				if rerr := register.Err(); !errors.Is(rerr, tc.wantErr) {
+					t.Errorf("Err(): got error = %v, want = %v", rerr, tc.wantErr)
+				}
+
+				if len(register.Flags()) != 0 {
+					t.Errorf("BoolVar(): flag must not be registered")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("BoolVar(): failed to register flag: %s", err)
+			}
+
+			if _, ok := register.LongFlag(tc.wantLong); !ok {
+				t.Errorf("LongFlag(%q): flag not found", tc.wantLong)
+			}
+		})
+	}
+}
+
+func TestRegister_with_long_pointer_helper(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Bool(&register, "verbose", WithShort("v"), WithLong("debug"))
+
+	want := &FlagError{Short: "v", Long: "verbose", Err: errDuplicateLong}
+
+	args := []string{"--verbose"}
+	if err := parser.Parse(nil, &register, args); !errors.Is(err, want) || !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Parse(%v): got error = %v, want error = %v", args, err, want)
+	}
+}
+
+func TestParser_AddFlagSet(t *testing.T) {
+	var (
+		db     FlagSet
+		parser DefaultParser
+	)
+
+	host := String(&db, "host", WithDefault("localhost"))
+	port := Int(&db, "port", WithDefault("5432"))
+	dsn := StringArg(&db, "dsn", Optional)
+
+	if err := parser.AddFlagSet(&db); err != nil {
+		t.Fatalf("AddFlagSet(): failed to add flag set: %s", err)
+	}
+
+	var first DefaultRegister
+	verbose := Bool(&first, "verbose")
+
+	args := []string{"--verbose", "--port", "6543", "postgres://"}
+	if err := parser.Parse(nil, &first, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if !*verbose || *host != "localhost" || *port != 6543 || *dsn != "postgres://" {
+		t.Errorf("Parse(%v): got = (%v, %q, %d, %q), want = (%v, %q, %d, %q)",
+			args, *verbose, *host, *port, *dsn, true, "localhost", 6543, "postgres://")
+	}
+
+	// The same register may be parsed again.
+	args = []string{"--host", "example.com"}
+	if err := parser.Parse(nil, &first, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if got, want := len(first.Flags()), 3; got != want {
+		t.Errorf("Flags(): got = %v, want = %v", got, want)
+	}
+
+	// Flags of the set are not set in other registers.
+	var second DefaultRegister
+	if err := parser.Parse(nil, &second, nil); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if flag, ok := second.LongFlag("host"); !ok || flag.Set() {
+		t.Errorf("LongFlag(%q): got = %v, %v, want unset flag", "host", flag, ok)
+	}
+
+	// Conflicts with flags of the register.
+	var third DefaultRegister
+	_ = Bool(&third, "host")
+
+	if err := parser.Parse(nil, &third, nil); !errors.Is(err, &FlagError{Long: "host", Err: ErrDuplicate}) {
+		t.Errorf("Parse(): got error = %v, want duplicate error", err)
+	}
+
+	// Registration errors of the set.
+	var broken FlagSet
+	_ = Bool(&broken, "-broken")
+
+	if err := parser.AddFlagSet(&broken); !errors.Is(err, &FlagError{Long: "-broken", Err: ErrInvalidName}) {
+		t.Errorf("AddFlagSet(): got error = %v, want invalid name error", err)
+	}
+}
+
+func TestParser_Reset(t *testing.T) {
+	var register DefaultRegister
+	parser := DefaultParser{
+		IgnoreUnknownFlags: true,
+	}
+
+	a := Int(&register, "a")
+	_ = StringArg(&register, "first", WithOptional())
+	_ = RestStrings(&register, "rest")
+	_ = Bool(&register, "a") // Duplicate.
+
+	if err := parser.Parse(nil, &register, []string{"-a", "1", "--unknown"}); !errors.Is(err, &FlagError{Short: "a", Err: ErrDuplicate}) {
+		t.Fatalf("Parse(): got error = %v, want duplicate error", err)
+	}
+
+	register.Reset()
+	parser.Reset()
+
+	if !parser.IgnoreUnknownFlags {
+		t.Errorf("Reset(): modes must not be changed")
+	}
+
+	if len(register.Flags()) != 0 || len(register.Args()) != 0 || register.Rest() != nil || register.Err() != nil {
+		t.Fatalf("Reset(): got flags = %v, args = %v, rest = %v, err = %v, want empty register",
+			register.Flags(), register.Args(), register.Rest(), register.Err())
+	}
+
+	// Re-register.
+	b := Int(&register, "a")
+	second := StringArg(&register, "second")
+
+	args := []string{"-a", "2", "--unknown", "value"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if *a != 0 {
+		t.Errorf("Parse(%v): old a: got = %v, want = %v", args, *a, 0)
+	}
+
+	if *b != 2 {
+		t.Errorf("Parse(%v): a: got = %v, want = %v", args, *b, 2)
+	}
+
+	if *second != "value" {
+		t.Errorf("Parse(%v): second: got = %q, want = %q", args, *second, "value")
+	}
+
+	if got := parser.Unknown(); !reflect.DeepEqual(got, []string{"--unknown"}) {
+		t.Errorf("Unknown(): got = %v, want = %v", got, []string{"--unknown"})
+	}
+}
+
+func TestRegister_ReserveFlags(t *testing.T) {
+	var register DefaultRegister
+	var parser DefaultParser
+
+	register.ReserveFlags(16)
+	register.ReserveArgs(16)
+
+	if got := cap(register.flags.data) - len(register.flags.data); got < 16 {
+		t.Errorf("ReserveFlags(): got free capacity = %d, want >= %d", got, 16)
+	}
+
+	if got := cap(register.args.data) - len(register.args.data); got < 16 {
+		t.Errorf("ReserveArgs(): got free capacity = %d, want >= %d", got, 16)
+	}
+
+	a := Int(&register, "a")
+	first := StringArg(&register, "first")
+
+	if err := parser.Parse(nil, &register, []string{"-a", "1", "one"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	flag := parser.Lookup("a")
+	if flag == nil || !flag.Set() {
+		t.Fatalf("Lookup(): got = %v, want set flag", flag)
+	}
+
+	if *a != 1 || *first != "one" {
+		t.Errorf("Reserve: got a = %v, first = %q, want a = %v, first = %q", *a, *first, 1, "one")
+	}
+
+	allocs := testing.AllocsPerRun(1, func() {
+		var register DefaultRegister
+		register.ReserveFlags(8)
+		for i := 0; i < 8; i++ {
+			register.flags.Add(Flag{})
+		}
+	})
+	if allocs > 2 {
+		t.Errorf("ReserveFlags(): got allocs = %v, want <= %v", allocs, 2)
+	}
+}
+
+func TestParser_Parse_short_long_only(t *testing.T) {
+	tt := []struct {
+		name        string
+		parser      DefaultParser
+		args        []string
+		wantVerbose bool
+		wantForce   bool
+		wantErr     error
+	}{
+		{
+			name:        "short only by short",
+			args:        []string{"-v"},
+			wantVerbose: true,
+		},
+		{
+			name:    "short only by long",
+			args:    []string{"--verbose"},
+			wantErr: &ParseFlagError{Name: "--verbose", Err: ErrUnknown},
+		},
+		{
+			name:      "long only by long",
+			args:      []string{"--force"},
+			wantForce: true,
+		},
+		{
+			name:    "long only by short",
+			args:    []string{"-f"},
+			wantErr: &ParseFlagError{Name: "-f", Err: ErrUnknown},
+		},
+		{
+			name:        "long only by short combined",
+			args:        []string{"-vf"},
+			wantVerbose: true,
+			wantErr:     &ParseFlagError{Name: "-f", Err: ErrUnknown},
+		},
+		{
+			name:        "universal short only",
+			parser:      DefaultParser{Universal: true},
+			args:        []string{"-v", "--force"},
+			wantVerbose: true,
+			wantForce:   true,
+		},
+		{
+			name:    "universal short only by long",
+			parser:  DefaultParser{Universal: true},
+			args:    []string{"-verbose"},
+			wantErr: &ParseFlagError{Name: "-verbose", Err: ErrUnknown},
+		},
+		{
+			name:    "universal long only by short",
+			parser:  DefaultParser{Universal: true},
+			args:    []string{"--f"},
+			wantErr: &ParseFlagError{Name: "-f", Err: ErrUnknown},
+		},
+		{
+			name:    "prefix match",
+			parser:  DefaultParser{PrefixMatch: true},
+			args:    []string{"--verb"},
+			wantErr: &ParseFlagError{Name: "--verb", Err: ErrUnknown},
+		},
+		{
+			name:    "negation",
+			parser:  DefaultParser{EnableNegation: true},
+			args:    []string{"--no-verbose"},
+			wantErr: &ParseFlagError{Name: "--no-verbose", Err: ErrUnknown},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var register DefaultRegister
+
+			parser := tc.parser
+
+			verbose := Bool(&register, "verbose", WithShort("v"), WithShortOnly())
+			force := Bool(&register, "force", WithShort("f"), WithLongOnly())
+
+			err := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+
+			if *verbose != tc.wantVerbose {
+				t.Errorf("Parse(%v): verbose: got = %v, want = %v", tc.args, *verbose, tc.wantVerbose)
+			}
+
+			if *force != tc.wantForce {
+				t.Errorf("Parse(%v): force: got = %v, want = %v", tc.args, *force, tc.wantForce)
+			}
+		})
+	}
+}
+
+func TestRegisterFlag_short_long_only(t *testing.T) {
+	tt := []struct {
+		name    string
+		options []FlagOptionApplyer
+		want    error
+	}{
+		{
+			name:    "short only without short",
+			options: []FlagOptionApplyer{WithShortOnly()},
+			want:    &FlagError{Long: "verbose", Err: ErrMissingName},
+		},
+		{
+			name:    "last option wins",
+			options: []FlagOptionApplyer{WithShort("v"), WithLongOnly(), WithShortOnly()},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var register DefaultRegister
+
+			if err := BoolVar(&register, new(bool), "verbose", tc.options...); !errors.Is(err, tc.want) {
+				t.Fatalf("BoolVar(): got error = %v, want error = %v", err, tc.want)
+			}
+		})
+	}
+
+	var register DefaultRegister
+
+	got := register.RegisterFlag(Flag{Value: newBoolValue(new(bool)), Short: "v", Long: "verbose", ShortOnly: true, LongOnly: true})
+	want := &FlagError{Short: "v", Long: "verbose", Err: ErrConflict}
+	if !errors.Is(got, want) {
+		t.Errorf("RegisterFlag(): got error = %v, want error = %v", got, want)
+	}
+}
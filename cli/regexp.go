@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"regexp"
+)
+
+// *regexp.Regexp
+
+var (
+	_ Value   = (*regexpValue)(nil)
+	_ Getter  = (*regexpValue)(nil)
+	_ Emptier = (*regexpValue)(nil)
+	_ Typer   = (*regexpValue)(nil)
+)
+
+type regexpValue struct {
+	p     **regexp.Regexp
+	posix bool // Use POSIX ERE syntax.
+}
+
+func newRegexpValue(p **regexp.Regexp, posix bool) *regexpValue {
+	if p == nil {
+		return nil
+	}
+
+	return &regexpValue{p: p, posix: posix}
+}
+
+func (v *regexpValue) Set(s string) error {
+	compile := regexp.Compile
+	if v.posix {
+		compile = regexp.CompilePOSIX
+	}
+
+	re, err := compile(s)
+	if err != nil {
+		return parseSyntaxError("regexp", s, err)
+	}
+
+	*v.p = re
+	return nil
+}
+
+func (v *regexpValue) Get() interface{} { return *v.p }
+
+func (v *regexpValue) Empty() bool { return *v.p == nil }
+
+func (v *regexpValue) String() string {
+	if v.p == nil || *v.p == nil {
+		return ""
+	}
+
+	return (*v.p).String()
+}
+
+func (*regexpValue) Type() string { return "regexp" }
+
+// RegexpVar defines a *regexp.Regexp flag with specified name.
+// The value is compiled by the regexp.Compile.
+// The argument p points to a *regexp.Regexp variable in which to store the value of the flag.
+//
+// Options are the same as for the cli.StringVar.
+func RegexpVar(register Register, p **regexp.Regexp, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newRegexpValue(p, false), name, options...)
+}
+
+// Regexp defines a *regexp.Regexp flag with specified name (see cli.RegexpVar).
+// The return value is the address of a *regexp.Regexp variable that stores the value of the flag.
+func Regexp(register Register, name string, options ...FlagOptionApplyer) **regexp.Regexp {
+	p := new(*regexp.Regexp)
+	_ = RegexpVar(register, p, name, options...)
+	return p
+}
+
+// RegexpArgVar defines a *regexp.Regexp argument with specified name (see cli.RegexpVar).
+// The argument p points to a *regexp.Regexp variable in which to store the value of the argument.
+func RegexpArgVar(register Register, p **regexp.Regexp, name string, options ...ArgOptionApplyer) error {
+	return ArgVar(register, newRegexpValue(p, false), name, options...)
+}
+
+// RegexpArg defines a *regexp.Regexp argument with specified name (see cli.RegexpVar).
+// The return value is the address of a *regexp.Regexp variable that stores the value of the argument.
+func RegexpArg(register Register, name string, options ...ArgOptionApplyer) **regexp.Regexp {
+	p := new(*regexp.Regexp)
+	_ = RegexpArgVar(register, p, name, options...)
+	return p
+}
+
+// RegexpPOSIXVar defines a *regexp.Regexp flag with specified name.
+// The value is compiled by the regexp.CompilePOSIX (POSIX ERE syntax with
+// leftmost-longest matching).
+// The argument p points to a *regexp.Regexp variable in which to store the value of the flag.
+//
+// Options are the same as for the cli.StringVar.
+func RegexpPOSIXVar(register Register, p **regexp.Regexp, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newRegexpValue(p, true), name, options...)
+}
+
+// RegexpPOSIX defines a *regexp.Regexp flag with specified name (see cli.RegexpPOSIXVar).
+// The return value is the address of a *regexp.Regexp variable that stores the value of the flag.
+func RegexpPOSIX(register Register, name string, options ...FlagOptionApplyer) **regexp.Regexp {
+	p := new(*regexp.Regexp)
+	_ = RegexpPOSIXVar(register, p, name, options...)
+	return p
+}
+
+// RegexpPOSIXArgVar defines a *regexp.Regexp argument with specified name (see cli.RegexpPOSIXVar).
+// The argument p points to a *regexp.Regexp variable in which to store the value of the argument.
+func RegexpPOSIXArgVar(register Register, p **regexp.Regexp, name string, options ...ArgOptionApplyer) error {
+	return ArgVar(register, newRegexpValue(p, true), name, options...)
+}
+
+// RegexpPOSIXArg defines a *regexp.Regexp argument with specified name (see cli.RegexpPOSIXVar).
+// The return value is the address of a *regexp.Regexp variable that stores the value of the argument.
+func RegexpPOSIXArg(register Register, name string, options ...ArgOptionApplyer) **regexp.Regexp {
+	p := new(*regexp.Regexp)
+	_ = RegexpPOSIXArgVar(register, p, name, options...)
+	return p
+}
@@ -1,9 +1,13 @@
 package cli
 
+import "time"
+
 type RestArgs struct {
 	Values Value
 	Name   string
 	Usage  Usager
+	Min    int // Minimum number of values.
+	Max    int // Maximum number of values. Unlimited if 0.
 
 	defaultSaved bool
 	defaultValue string
@@ -15,6 +19,8 @@ func newRest(values Value, opts RestOptions) RestArgs {
 		Values: values,
 		Name:   opts.Name,
 		Usage:  opts.Usage,
+		Min:    opts.Min,
+		Max:    opts.Max,
 	}
 }
 
@@ -52,7 +58,7 @@ func (ra *RestArgs) Default() (v string, empty bool) {
 
 func (ra *RestArgs) SaveDefault() {
 	if ra.Values != nil {
-		ra.defaultValue = ra.Values.String()
+		ra.defaultValue = defaultString(ra.Values)
 		if ev, ok := ra.Values.(Emptier); ok {
 			ra.defaultEmpty = ev.Empty()
 		} else {
@@ -71,4 +77,19 @@ func RestVar(register Register, value Value, name string, options ...RestOptionA
 	return register.RegisterRestArgs(newRest(value, opts))
 }
 
+// IntRestArgs is an alias of the cli.RestInts.
+func IntRestArgs(register Register, name string, options ...RestOptionApplyer) *[]int {
+	return RestInts(register, name, options...)
+}
+
+// Float64RestArgs is an alias of the cli.RestFloat64s.
+func Float64RestArgs(register Register, name string, options ...RestOptionApplyer) *[]float64 {
+	return RestFloat64s(register, name, options...)
+}
+
+// DurationRestArgs is an alias of the cli.RestDurations.
+func DurationRestArgs(register Register, name string, options ...RestOptionApplyer) *[]time.Duration {
+	return RestDurations(register, name, options...)
+}
+
 //go:generate python ./generate_rest.py
@@ -0,0 +1,64 @@
+package cli
+
+// sensitive string
+
+var (
+	_ Value      = (*sensitiveValue)(nil)
+	_ Getter     = (*sensitiveValue)(nil)
+	_ Emptier    = (*sensitiveValue)(nil)
+	_ Typer      = (*sensitiveValue)(nil)
+	_ stringFlag = (*sensitiveValue)(nil)
+)
+
+// sensitiveMask replaces values of sensitive flags in the output.
+const sensitiveMask = "***"
+
+type sensitiveValue struct {
+	p *string
+}
+
+func newSensitiveValue(p *string) *sensitiveValue {
+	if p == nil {
+		return nil
+	}
+
+	return &sensitiveValue{p: p}
+}
+
+func (v *sensitiveValue) Set(s string) error {
+	*v.p = s
+	return nil
+}
+
+func (v *sensitiveValue) Get() interface{} { return *v.p }
+
+func (v *sensitiveValue) Empty() bool { return *v.p == "" }
+
+// String always returns the mask, so the value doesn't leak into the help or
+// logs.
+func (*sensitiveValue) String() string { return sensitiveMask }
+
+func (*sensitiveValue) Type() string { return "string" }
+
+func (*sensitiveValue) IsStringFlag() bool { return true }
+
+// SensitiveStringVar defines a string flag with specified name for passwords,
+// tokens and other secrets. The String method of the flag value returns "***"
+// and the help doesn't show the default value of the flag.
+// The argument p points to a string variable in which to store the value of the flag.
+//
+// Options are the same as for the cli.StringVar.
+func SensitiveStringVar(register Register, p *string, name string, options ...FlagOptionApplyer) error {
+	options = append(append([]FlagOptionApplyer(nil), options...), WithSensitive())
+
+	return Var(register, newSensitiveValue(p), name, options...)
+}
+
+// SensitiveString defines a sensitive string flag with specified name (see
+// cli.SensitiveStringVar).
+// The return value is the address of a string variable that stores the value of the flag.
+func SensitiveString(register Register, name string, options ...FlagOptionApplyer) *string {
+	p := new(string)
+	_ = SensitiveStringVar(register, p, name, options...)
+	return p
+}
@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Slices are similar to multi flags (e.g. cli.Strings) but every occurrence of
+// the flag is a single value (it is not split by commas) and String() returns
+// a JSON array.
+
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+// eachValue calls fn for val or for every comma separated part of val.
+func eachValue(val string, comma bool, fn func(string) error) error {
+	if !comma {
+		return fn(val)
+	}
+
+	for _, v := range strings.Split(val, ",") {
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// []string
+
+var (
+	_ Value   = (*stringSliceValue)(nil)
+	_ Getter  = (*stringSliceValue)(nil)
+	_ Emptier = (*stringSliceValue)(nil)
+	_ Typer   = (*stringSliceValue)(nil)
+)
+
+type stringSliceValue struct {
+	p     *[]string
+	comma bool // Split values by comma.
+}
+
+func newStringSliceValue(p *[]string, comma bool) *stringSliceValue {
+	if p == nil {
+		return nil
+	}
+
+	return &stringSliceValue{p: p, comma: comma}
+}
+
+func (v *stringSliceValue) Set(val string) error {
+	return eachValue(val, v.comma, func(s string) error {
+		*v.p = append(*v.p, s)
+		return nil
+	})
+}
+
+func (v *stringSliceValue) Get() interface{} { return *v.p }
+
+func (v *stringSliceValue) Empty() bool { return len(*v.p) == 0 }
+
+func (v *stringSliceValue) String() string {
+	if v.p == nil || *v.p == nil {
+		return "[]"
+	}
+
+	return jsonString(*v.p)
+}
+
+func (*stringSliceValue) Type() string { return "[]string" }
+
+// StringSliceVar defines a []string flag with specified name.
+// Every occurrence of the flag appends its value to the slice:
+// "--tag foo --tag bar" is []string{"foo", "bar"}.
+// The argument p points to a []string variable in which to store values of the flag.
+//
+// Options are the same as for the cli.StringVar.
+func StringSliceVar(register Register, p *[]string, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newStringSliceValue(p, false), name, options...)
+}
+
+// StringSlice defines a []string flag with specified name (see cli.StringSliceVar).
+// The return value is the address of a []string variable that stores values of the flag.
+func StringSlice(register Register, name string, options ...FlagOptionApplyer) *[]string {
+	p := new([]string)
+	_ = StringSliceVar(register, p, name, options...)
+	return p
+}
+
+// StringSliceArgVar defines a []string argument with specified name.
+// The argument takes a single positional slot with comma separated values:
+// "a,b,c" is []string{"a", "b", "c"}.
+// The argument p points to a []string variable in which to store values of the argument.
+//
+// Options are the same as for the cli.StringArgVar.
+func StringSliceArgVar(register Register, p *[]string, name string, options ...ArgOptionApplyer) error {
+	return ArgVar(register, newStringSliceValue(p, true), name, options...)
+}
+
+// StringSliceArg defines a []string argument with specified name (see cli.StringSliceArgVar).
+// The return value is the address of a []string variable that stores values of the argument.
+func StringSliceArg(register Register, name string, options ...ArgOptionApplyer) *[]string {
+	p := new([]string)
+	_ = StringSliceArgVar(register, p, name, options...)
+	return p
+}
+
+// []int
+
+var (
+	_ Value   = (*intSliceValue)(nil)
+	_ Getter  = (*intSliceValue)(nil)
+	_ Emptier = (*intSliceValue)(nil)
+	_ Typer   = (*intSliceValue)(nil)
+)
+
+type intSliceValue []int
+
+func newIntSliceValue(p *[]int) *intSliceValue {
+	return (*intSliceValue)(p)
+}
+
+func (vs *intSliceValue) Set(val string) error {
+	var v int
+	if err := newIntValue(&v).Set(val); err != nil {
+		return err
+	}
+
+	*vs = append(*vs, v)
+
+	return nil
+}
+
+func (vs *intSliceValue) Get() interface{} { return []int(*vs) }
+
+func (vs *intSliceValue) Empty() bool { return len(*vs) == 0 }
+
+func (vs *intSliceValue) String() string {
+	if *vs == nil {
+		return "[]"
+	}
+
+	return jsonString([]int(*vs))
+}
+
+func (*intSliceValue) Type() string { return "[]int" }
+
+// IntSliceVar defines a []int flag with specified name.
+// Every occurrence of the flag appends its value to the slice:
+// "--port 8080 --port 9090" is []int{8080, 9090}.
+// The argument p points to a []int variable in which to store values of the flag.
+//
+// Options are the same as for the cli.IntVar.
+func IntSliceVar(register Register, p *[]int, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newIntSliceValue(p), name, options...)
+}
+
+// IntSlice defines a []int flag with specified name (see cli.IntSliceVar).
+// The return value is the address of a []int variable that stores values of the flag.
+func IntSlice(register Register, name string, options ...FlagOptionApplyer) *[]int {
+	p := new([]int)
+	_ = IntSliceVar(register, p, name, options...)
+	return p
+}
+
+// []int64
+
+var (
+	_ Value   = (*int64SliceValue)(nil)
+	_ Getter  = (*int64SliceValue)(nil)
+	_ Emptier = (*int64SliceValue)(nil)
+	_ Typer   = (*int64SliceValue)(nil)
+)
+
+type int64SliceValue []int64
+
+func newInt64SliceValue(p *[]int64) *int64SliceValue {
+	return (*int64SliceValue)(p)
+}
+
+func (vs *int64SliceValue) Set(val string) error {
+	var v int64
+	if err := newInt64Value(&v).Set(val); err != nil {
+		return err
+	}
+
+	*vs = append(*vs, v)
+
+	return nil
+}
+
+func (vs *int64SliceValue) Get() interface{} { return []int64(*vs) }
+
+func (vs *int64SliceValue) Empty() bool { return len(*vs) == 0 }
+
+func (vs *int64SliceValue) String() string {
+	if *vs == nil {
+		return "[]"
+	}
+
+	return jsonString([]int64(*vs))
+}
+
+func (*int64SliceValue) Type() string { return "[]int64" }
+
+// Int64SliceVar defines a []int64 flag with specified name (see cli.IntSliceVar).
+// The argument p points to a []int64 variable in which to store values of the flag.
+func Int64SliceVar(register Register, p *[]int64, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newInt64SliceValue(p), name, options...)
+}
+
+// Int64Slice defines a []int64 flag with specified name (see cli.IntSliceVar).
+// The return value is the address of a []int64 variable that stores values of the flag.
+func Int64Slice(register Register, name string, options ...FlagOptionApplyer) *[]int64 {
+	p := new([]int64)
+	_ = Int64SliceVar(register, p, name, options...)
+	return p
+}
@@ -0,0 +1,79 @@
+package cli
+
+// ParserSnapshot is the state of the last parse of a DefaultParser (see
+// DefaultParser.Snapshot).
+type ParserSnapshot struct {
+	register    Register
+	flagsSet    []bool
+	argsSet     []bool
+	unknown     []string
+	rest        []string
+	passthrough []string
+	commands    []string
+}
+
+// Snapshot returns a copy of the state of the last Parse: markers of set
+// flags and args of the register, unknown flags, rest args and the command
+// path. Values of flags and args are not copied.
+func (p *DefaultParser) Snapshot() ParserSnapshot {
+	s := ParserSnapshot{
+		register:    p.register,
+		unknown:     append([]string(nil), p.unknown...),
+		rest:        append([]string(nil), p.rest...),
+		passthrough: append([]string(nil), p.passthrough...),
+	}
+
+	if p.commands != nil {
+		s.commands = append([]string{}, p.commands...)
+	}
+
+	if p.register == nil {
+		return s
+	}
+
+	flags := p.register.Flags()
+	s.flagsSet = make([]bool, len(flags))
+	for i := range flags {
+		s.flagsSet[i] = flags[i].Set()
+	}
+
+	args := p.register.Args()
+	s.argsSet = make([]bool, len(args))
+	for i := range args {
+		s.argsSet[i] = args[i].Set()
+	}
+
+	return s
+}
+
+// Restore resets the state of the last Parse to the snapshot. Modes and
+// registered flags are not changed. It returns the ErrSnapshotMismatch if
+// flags or args were registered in the register after the snapshot.
+func (p *DefaultParser) Restore(s ParserSnapshot) error {
+	if s.register != nil {
+		flags := s.register.Flags()
+		args := s.register.Args()
+		if len(flags) != len(s.flagsSet) || len(args) != len(s.argsSet) {
+			return ErrSnapshotMismatch
+		}
+
+		for i := range flags {
+			flags[i].set = s.flagsSet[i]
+		}
+
+		for i := range args {
+			args[i].set = s.argsSet[i]
+		}
+	}
+
+	p.register = s.register
+	p.unknown = append(p.unknown[:0], s.unknown...)
+	p.rest = append(p.rest[:0], s.rest...)
+	p.passthrough = append(p.passthrough[:0], s.passthrough...)
+	p.commands = nil
+	if s.commands != nil {
+		p.commands = append([]string{}, s.commands...)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParser_Snapshot(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	parser.IgnoreUnknownFlags = true
+	parser.IgnoreUnknownArgs = true
+
+	verbose := Bool(&register, "verbose")
+	_ = Int(&register, "count")
+	_ = StringArg(&register, "file", Optional)
+
+	commander := NewStaticCommander(map[string]func(Register) error{})
+
+	args := []string{"--verbose", "--unknown", "a.txt", "b.txt"}
+	if err := parser.Parse(commander, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	snapshot := parser.Snapshot()
+
+	args = []string{"--count", "1"}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if err := parser.Restore(snapshot); err != nil {
+		t.Fatalf("Restore(): failed to restore snapshot: %s", err)
+	}
+
+	// Values are not restored.
+	if !*verbose {
+		t.Errorf("Restore(): verbose: got = %v, want = %v", *verbose, true)
+	}
+
+	var set []string
+	parser.Visit(func(flag *Flag, _ bool) {
+		set = append(set, flag.name())
+	})
+
+	if want := []string{"verbose"}; !reflect.DeepEqual(set, want) {
+		t.Errorf("Visit(): got = %v, want = %v", set, want)
+	}
+
+	if arg, _ := register.Arg(0); !arg.Set() {
+		t.Errorf("Restore(): arg %q must be set", arg.Name)
+	}
+
+	if got, want := parser.Unknown(), []string{"--unknown"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unknown(): got = %v, want = %v", got, want)
+	}
+
+	if got, want := parser.RestArgs(), []string{"b.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RestArgs(): got = %v, want = %v", got, want)
+	}
+
+	if got := parser.ParsedCommands(); got == nil || len(got) != 0 {
+		t.Errorf("ParsedCommands(): got = %#v, want empty path", got)
+	}
+
+	// Registered flags don't match the snapshot.
+	_ = Bool(&register, "new")
+
+	if err := parser.Restore(snapshot); err != ErrSnapshotMismatch {
+		t.Errorf("Restore(): got error = %v, want = %v", err, ErrSnapshotMismatch)
+	}
+}
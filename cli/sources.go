@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// valueSource provides values for flags which were not set in the arguments.
+type valueSource interface {
+	lookup(flag *Flag) (values []string, ok bool)
+}
+
+// envValueSource is a source backed by environment variables.
+type envValueSource interface {
+	valueSource
+	variable(flag *Flag) string
+}
+
+type EnvError struct {
+	Name string
+	Err  error
+}
+
+func (e *EnvError) Error() string {
+	msg := "unknown error"
+	if e.Err != nil {
+		msg = e.Err.Error()
+	}
+
+	// Do not add "cli: " prefix. It's not a top level error.
+	return fmt.Sprintf("env %s: %s", e.Name, msg)
+}
+
+func (e *EnvError) Unwrap() error { return e.Err }
+
+func (e *EnvError) Is(err error) bool {
+	pe, ok := err.(*EnvError)
+	return ok && pe.Name == e.Name && errors.Is(pe.Err, e.Err)
+}
+
+func lookupEnv(name string) ([]string, bool) {
+	if name == "" {
+		return nil, false
+	}
+
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, false
+	}
+
+	return []string{v}, true
+}
+
+var _ envValueSource = envSource{}
+
+type envSource struct {
+	prefix string
+}
+
+func (s envSource) lookup(flag *Flag) ([]string, bool) {
+	return lookupEnv(s.variable(flag))
+}
+
+func (s envSource) variable(flag *Flag) string { return envName(s.prefix, flag) }
+
+var _ envValueSource = flagEnvSource{}
+
+// flagEnvSource looks up the variable set by the cli.WithEnv.
+type flagEnvSource struct{}
+
+func (s flagEnvSource) lookup(flag *Flag) ([]string, bool) {
+	return lookupEnv(s.variable(flag))
+}
+
+func (flagEnvSource) variable(flag *Flag) string { return flag.Env }
+
+// AutoEnv backs every flag by an environment variable with the prefix (see
+// the envName for the conversion rules). Variables set by the cli.WithEnv
+// take precedence over automatic ones.
+//
+//   parser.AutoEnv("MYAPP") // --log-level <- MYAPP_LOG_LEVEL
+func (p *DefaultParser) AutoEnv(prefix string) {
+	p.sources = append(p.sources, envSource{prefix: prefix})
+}
+
+// ParseFromEnv sets flags of the r which are not set yet from environment
+// variables with the prefix (see the envName for the conversion rules). It
+// returns the first error of values. It does nothing in the DisableEnv mode.
+//
+// Called before the Parse it makes the environment a base layer which is
+// overridden by arguments:
+//
+//   if err := parser.ParseFromEnv(&register, "MYAPP"); err != nil {
+//       return err
+//   }
+//
+//   if err := parser.Parse(nil, &register, os.Args[1:]); err != nil {
+//       return err
+//   }
+//
+// Called after the Parse it fills only flags missing in arguments, but the
+// Parse reports missing required flags before it. Use AutoEnv to satisfy
+// required flags from the environment.
+func (p *DefaultParser) ParseFromEnv(r Register, prefix string) error {
+	if p.DisableEnv {
+		return nil
+	}
+
+	return p.parseFromSource(r, envSource{prefix: prefix}, false)
+}
+
+func (p *DefaultParser) parseFromSource(r Register, source valueSource, override bool) error {
+	// Save defaults for the help before values are changed.
+	if err := p.prepareRegister(r); err != nil {
+		return err
+	}
+
+	flags := r.Flags()
+	for i := range flags {
+		flag := &flags[i]
+
+		// Command flags can be set only by arguments.
+		if (flag.Set() && !override) || flag.commandFlag {
+			continue
+		}
+
+		if _, err := p.applySource(source, flag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// envName converts the flag name into an environment variable name:
+// "log-level" with "MYAPP" prefix becomes "MYAPP_LOG_LEVEL".
+func envName(prefix string, flag *Flag) string {
+	name := flag.name()
+	if name == "" {
+		return ""
+	}
+
+	name = strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "_" + name
+}
+
+var _ valueSource = mapSource{}
+
+type mapSource map[string]string
+
+func (s mapSource) lookup(flag *Flag) ([]string, bool) {
+	var (
+		v  string
+		ok bool
+	)
+	// Short-only flags have no long key.
+	if flag.Long != "" {
+		v, ok = s[flag.Long]
+	}
+
+	if !ok && flag.Short != "" {
+		v, ok = s[flag.Short]
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	return []string{v}, true
+}
+
+// ParseFromMap sets flags of the r from the map by long names or short names
+// if a long one is missing, and positional args by their names. Flags and
+// args which are already set (e.g. by the Parse) are skipped unless the
+// OverrideFlags or OverrideArgs mode is enabled. It returns the first error
+// of values. It may be called before or after the Parse (see ParseFromEnv).
+//
+//   err := parser.ParseFromMap(&register, map[string]string{"log-level": "debug"})
+func (p *DefaultParser) ParseFromMap(r Register, m map[string]string) error {
+	if err := p.parseFromSource(r, mapSource(m), p.OverrideFlags); err != nil {
+		return err
+	}
+
+	args := r.Args()
+	for i := range args {
+		arg := &args[i]
+
+		if arg.Set() && !p.OverrideArgs {
+			continue
+		}
+
+		value, ok := m[arg.Name]
+		if !ok {
+			continue
+		}
+
+		if err := p.set(arg.Name, value, arg.Value); err != nil {
+			return &ArgError{
+				Name:  arg.Name,
+				Index: i,
+				Err:   err,
+			}
+		}
+
+		arg.MarkSet()
+	}
+
+	return nil
+}
+
+var _ valueSource = jsonSource{}
+
+type jsonSource struct {
+	values map[string]json.RawMessage
+}
+
+func newJSONSource(r io.Reader) (jsonSource, error) {
+	var values map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&values); err != nil {
+		return jsonSource{}, err
+	}
+
+	return jsonSource{values: values}, nil
+}
+
+func (s jsonSource) lookup(flag *Flag) ([]string, bool) {
+	var (
+		raw json.RawMessage
+		ok  bool
+	)
+	// Short-only flags have no long key.
+	if flag.Long != "" {
+		raw, ok = s.values[flag.Long]
+	}
+
+	if !ok && flag.Short != "" {
+		raw, ok = s.values[flag.Short]
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	// Arrays are set element by element, so multi flags get all values.
+	var elems []json.RawMessage
+	if err := json.Unmarshal(raw, &elems); err != nil {
+		elems = []json.RawMessage{raw}
+	}
+
+	values := make([]string, 0, len(elems))
+	for _, elem := range elems {
+		var s string
+		if err := json.Unmarshal(elem, &s); err == nil {
+			values = append(values, s)
+			continue
+		}
+
+		if string(elem) == "null" {
+			continue
+		}
+
+		values = append(values, string(elem))
+	}
+
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	return values, true
+}
@@ -0,0 +1,272 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	tt := []struct {
+		prefix string
+		flag   Flag
+		want   string
+	}{
+		{prefix: "MYAPP", flag: Flag{Long: "log-level"}, want: "MYAPP_LOG_LEVEL"},
+		{prefix: "MYAPP", flag: Flag{Short: "v"}, want: "MYAPP_V"},
+		{prefix: "MYAPP", flag: Flag{Short: "v", Long: "verbose"}, want: "MYAPP_VERBOSE"},
+		{prefix: "", flag: Flag{Long: "log-level"}, want: "LOG_LEVEL"},
+		{prefix: "MYAPP", flag: Flag{}, want: ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.want, func(t *testing.T) {
+			if got := envName(tc.prefix, &tc.flag); got != tc.want {
+				t.Errorf("envName(%q, %s): got = %q, want = %q", tc.prefix, &tc.flag, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDefaultParser_AutoEnv(t *testing.T) {
+	defer setenv(t, "NICETEST_LOG_LEVEL", "warn")()
+	defer setenv(t, "NICETEST_PORT", "8080")()
+	defer setenv(t, "NICETEST_CUSTOM_PORT", "9090")()
+
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	parser.AutoEnv("NICETEST")
+
+	logLevel := String(&register, "log-level")
+	port := Int(&register, "port", WithEnv("NICETEST_CUSTOM_PORT"))
+
+	if err := parser.Parse(nil, &register, nil); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if *logLevel != "warn" {
+		t.Errorf("Parse(): got log-level = %q, want = %q", *logLevel, "warn")
+	}
+
+	// WithEnv wins.
+	if *port != 9090 {
+		t.Errorf("Parse(): got port = %d, want = %d", *port, 9090)
+	}
+}
+
+func TestDefaultParser_ParseFromEnv(t *testing.T) {
+	defer setenv(t, "NICETEST_LOG_LEVEL", "warn")()
+	defer setenv(t, "NICETEST_PORT", "8080")()
+	defer setenv(t, "NICETEST_DRY_RUN", "true")()
+
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	logLevel := String(&register, "log-level", WithDefault("info"))
+	port := Int(&register, "port")
+	dryRun := Bool(&register, "dry-run")
+	timeout := Int(&register, "timeout")
+
+	// Before the Parse the environment is a base layer.
+	if err := parser.ParseFromEnv(&register, "NICETEST"); err != nil {
+		t.Fatalf("ParseFromEnv(): failed to parse env: %s", err)
+	}
+
+	args := []string{"--port", "3000"}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if *logLevel != "warn" {
+		t.Errorf("ParseFromEnv(): got log-level = %q, want = %q", *logLevel, "warn")
+	}
+
+	// Arguments win.
+	if *port != 3000 {
+		t.Errorf("ParseFromEnv(): got port = %d, want = %d", *port, 3000)
+	}
+
+	if !*dryRun {
+		t.Errorf("ParseFromEnv(): got dry-run = %v, want = %v", *dryRun, true)
+	}
+
+	if *timeout != 0 {
+		t.Errorf("ParseFromEnv(): got timeout = %d, want = %d", *timeout, 0)
+	}
+
+	// The help shows the registered default.
+	flag, _ := register.LongFlag("log-level")
+	if v, _ := flag.Default(); v != "info" {
+		t.Errorf("ParseFromEnv(): got log-level default = %q, want = %q", v, "info")
+	}
+
+	defer setenv(t, "NICETEST_TIMEOUT", "never")()
+
+	// After the Parse it fills flags missing in arguments.
+	err := parser.ParseFromEnv(&register, "NICETEST")
+
+	var pe *ParseValueError
+	var fe *FlagError
+	if !errors.As(err, &fe) || !errors.As(fe.Err, &pe) {
+		t.Fatalf("ParseFromEnv(): got error = %v, want parse value error", err)
+	}
+
+	if pe.Input != "never" {
+		t.Errorf("ParseFromEnv(): got input = %q, want = %q", pe.Input, "never")
+	}
+}
+
+func TestDefaultParser_ParseFromEnv_disabled(t *testing.T) {
+	defer setenv(t, "NICETEST_PORT", "8080")()
+
+	var register DefaultRegister
+
+	parser := DefaultParser{DisableEnv: true}
+
+	port := Int(&register, "port")
+
+	if err := parser.ParseFromEnv(&register, "NICETEST"); err != nil {
+		t.Fatalf("ParseFromEnv(): failed to parse env: %s", err)
+	}
+
+	if *port != 0 {
+		t.Errorf("ParseFromEnv(): got port = %d, want = %d", *port, 0)
+	}
+}
+
+func TestDefaultParser_ParseFromMap(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	logLevel := String(&register, "log-level")
+	port := Int(&register, "port", WithShort("p"))
+	verbose := Bool(&register, "v")
+	timeout := Int(&register, "timeout")
+	retries := Int(&register, "retries")
+
+	// Before the Parse the map is a base layer.
+	m := map[string]string{"retries": "3"}
+	if err := parser.ParseFromMap(&register, m); err != nil {
+		t.Fatalf("ParseFromMap(%v): failed to parse map: %s", m, err)
+	}
+
+	args := []string{"--port", "3000"}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	m = map[string]string{
+		"log-level": "debug",
+		"p":         "8080",
+		"v":         "true",
+	}
+	if err := parser.ParseFromMap(&register, m); err != nil {
+		t.Fatalf("ParseFromMap(%v): failed to parse map: %s", m, err)
+	}
+
+	if *logLevel != "debug" {
+		t.Errorf("ParseFromMap(%v): got log-level = %q, want = %q", m, *logLevel, "debug")
+	}
+
+	// Arguments win.
+	if *port != 3000 {
+		t.Errorf("ParseFromMap(%v): got port = %d, want = %d", m, *port, 3000)
+	}
+
+	if !*verbose {
+		t.Errorf("ParseFromMap(%v): got v = %v, want = %v", m, *verbose, true)
+	}
+
+	parser.OverrideFlags = true
+
+	m = map[string]string{
+		"port":    "9090",
+		"p":       "1",
+		"timeout": "never",
+	}
+	err := parser.ParseFromMap(&register, m)
+
+	var pe *ParseValueError
+	var fe *FlagError
+	if !errors.As(err, &fe) || !errors.As(fe.Err, &pe) {
+		t.Fatalf("ParseFromMap(%v): got error = %v, want parse value error", m, err)
+	}
+
+	if fe.Long != "timeout" || pe.Input != "never" {
+		t.Errorf("ParseFromMap(%v): got flag = %q, input = %q, want flag = %q, input = %q",
+			m, fe.Long, pe.Input, "timeout", "never")
+	}
+
+	// Long names win.
+	if *port != 9090 {
+		t.Errorf("ParseFromMap(%v): got port = %d, want = %d", m, *port, 9090)
+	}
+
+	if *timeout != 0 {
+		t.Errorf("ParseFromMap(%v): got timeout = %d, want = %d", m, *timeout, 0)
+	}
+
+	if *retries != 3 {
+		t.Errorf("ParseFromMap(%v): got retries = %d, want = %d", m, *retries, 3)
+	}
+}
+
+func TestDefaultParser_ParseFromMap_args(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	src := StringArg(&register, "src")
+	dst := StringArg(&register, "dst", Optional)
+
+	args := []string{"a"}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	m := map[string]string{"src": "b", "dst": "c"}
+	if err := parser.ParseFromMap(&register, m); err != nil {
+		t.Fatalf("ParseFromMap(%v): failed to parse map: %s", m, err)
+	}
+
+	// Arguments win.
+	if *src != "a" || *dst != "c" {
+		t.Errorf("ParseFromMap(%v): got src = %q, dst = %q, want src = %q, dst = %q", m, *src, *dst, "a", "c")
+	}
+
+	parser.OverrideArgs = true
+
+	if err := parser.ParseFromMap(&register, m); err != nil {
+		t.Fatalf("ParseFromMap(%v): failed to parse map: %s", m, err)
+	}
+
+	if *src != "b" {
+		t.Errorf("ParseFromMap(%v): got src = %q, want = %q", m, *src, "b")
+	}
+}
+
+func TestDefaultParser_ParseFromMap_empty_key(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	n := Int(&register, "n")
+
+	m := map[string]string{"": "1"}
+	if err := parser.ParseFromMap(&register, m); err != nil {
+		t.Fatalf("ParseFromMap(%v): failed to parse map: %s", m, err)
+	}
+
+	// Short-only flags must not be set by the empty key.
+	if *n != 0 {
+		t.Errorf("ParseFromMap(%v): got n = %d, want = %d", m, *n, 0)
+	}
+}
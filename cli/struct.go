@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported type")
+
+	ErrInvalidTag = errors.New("invalid tag")
+)
+
+type StructFieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *StructFieldError) Error() string {
+	msg := "unknown error"
+	if e.Err != nil {
+		msg = e.Err.Error()
+	}
+
+	if e.Field == "" {
+		return fmt.Sprintf("cli: struct error: %s", msg)
+	}
+
+	return fmt.Sprintf("cli: struct error: field '%s': %s", e.Field, msg)
+}
+
+func (e *StructFieldError) Unwrap() error { return e.Err }
+
+func (e *StructFieldError) Is(err error) bool {
+	pe, ok := err.(*StructFieldError)
+	return ok && pe.Field == e.Field && errors.Is(pe.Err, e.Err)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// RegisterStruct registers every exported field of the struct pointed by v
+// as a flag. Fields are configured by the "cli" tag with comma separated
+// options:
+//
+//   type Config struct {
+//       Port    int    `cli:"long=port,short=p,usage=Port to listen,required"`
+//       Host    string `cli:"env=HOST,default=localhost"`
+//       Verbose bool   `cli:"short=v"`
+//       Secret  string `cli:"-"` // Skipped.
+//   }
+//
+// The long name is the kebab-cased field name if neither long nor short is
+// set (e.g. LogLevel is --log-level). Usages cannot contain commas.
+//
+// Supported types are bool, int, int64, uint, uint64, float64, string and
+// time.Duration. Embedded structs and pointers to structs are processed
+// recursively, nil pointers are allocated (unless the embedded type is
+// unexported, then ErrNilTarget is returned). After the Parse fields hold
+// parsed values.
+func RegisterStruct(register Register, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return &StructFieldError{Err: ErrUnsupportedType}
+	}
+
+	return registerStruct(register, rv.Elem(), "")
+}
+
+func registerStruct(register Register, rv reflect.Value, prefix string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fieldName := prefix + field.Name
+
+		tag, hasTag := field.Tag.Lookup("cli")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && !hasTag {
+			if err := registerStruct(register, rv.Field(i), fieldName+"."); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct && !hasTag {
+			fv := rv.Field(i)
+			if fv.IsNil() {
+				// Nil pointers of unexported types cannot be allocated.
+				if !fv.CanSet() {
+					return &StructFieldError{Field: fieldName, Err: ErrNilTarget}
+				}
+
+				fv.Set(reflect.New(field.Type.Elem()))
+			}
+
+			if err := registerStruct(register, fv.Elem(), fieldName+"."); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		// Unexported.
+		if field.PkgPath != "" {
+			continue
+		}
+
+		opts, err := parseStructTag(tag)
+		if err != nil {
+			return &StructFieldError{Field: fieldName, Err: err}
+		}
+
+		if opts.Short == "" && opts.Long == "" {
+			opts.Long = kebabCase(field.Name)
+		}
+
+		// Names are set by the options.
+		if err := registerStructField(register, rv.Field(i), "", opts); err != nil {
+			if err == ErrUnsupportedType {
+				return &StructFieldError{Field: fieldName, Err: err}
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func registerStructField(register Register, fv reflect.Value, name string, opts FlagOptions) error {
+	p := fv.Addr().Interface()
+
+	// time.Duration is an int64, so check it first.
+	if fv.Type() == durationType {
+		return DurationVar(register, p.(*time.Duration), name, opts)
+	}
+
+	switch p := p.(type) {
+	case *bool:
+		return BoolVar(register, p, name, opts)
+	case *int:
+		return IntVar(register, p, name, opts)
+	case *int64:
+		return Int64Var(register, p, name, opts)
+	case *uint:
+		return UintVar(register, p, name, opts)
+	case *uint64:
+		return Uint64Var(register, p, name, opts)
+	case *float64:
+		return Float64Var(register, p, name, opts)
+	case *string:
+		return StringVar(register, p, name, opts)
+	default:
+		return ErrUnsupportedType
+	}
+}
+
+func parseStructTag(tag string) (FlagOptions, error) {
+	var opts FlagOptions
+	if tag == "" {
+		return opts, nil
+	}
+
+	for _, part := range strings.Split(tag, ",") {
+		key, value := part, ""
+		hasValue := false
+		if idx := strings.IndexByte(part, '='); idx != -1 {
+			key, value = part[:idx], part[idx+1:]
+			hasValue = true
+		}
+
+		// Only "required" doesn't have a value.
+		if (key == "required") == hasValue {
+			return opts, ErrInvalidTag
+		}
+
+		switch key {
+		case "long":
+			opts.Long = value
+		case "short":
+			opts.Short = value
+		case "usage":
+			if value != "" {
+				opts.Usage = Usage(value)
+			}
+		case "required":
+			opts.Necessary = Required
+		case "env":
+			opts.Env = value
+		case "default":
+			opts.defaultValue = &value
+		default:
+			return opts, ErrInvalidTag
+		}
+	}
+
+	return opts, nil
+}
+
+// kebabCase converts a field name into a flag name: "LogLevel" is "log-level",
+// "HTTPPort" is "http-port".
+func kebabCase(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word on a lower-upper or an upper-lower boundary ("HTTPPort").
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1]))) {
+				_ = b.WriteByte('-')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		_, _ = b.WriteRune(r)
+	}
+
+	return b.String()
+}
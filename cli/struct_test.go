@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testStructCommon struct {
+	Verbose bool `cli:"short=v,usage=Verbose output"`
+}
+
+type testStruct struct {
+	testStructCommon
+
+	Int      int           `cli:"long=int,short=i"`
+	Int64    int64         `cli:"default=-42"`
+	Uint     uint          `cli:"short=u"`
+	Uint64   uint64        `cli:"required"`
+	Float64  float64       `cli:"long=ratio"`
+	String   string        `cli:"env=NICETEST_STRUCT_STRING"`
+	Duration time.Duration `cli:"default=1s"`
+	LogLevel string
+	Skipped  string `cli:"-"`
+
+	unexported string
+}
+
+func TestRegisterStruct(t *testing.T) {
+	defer setenv(t, "NICETEST_STRUCT_STRING", "from env")()
+
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		config   testStruct
+	)
+
+	if err := RegisterStruct(&register, &config); err != nil {
+		t.Fatalf("RegisterStruct(): failed to register struct: %s", err)
+	}
+
+	args := []string{
+		"-v",
+		"-i", "1",
+		"-u", "3",
+		"--uint64", "4",
+		"--ratio", "0.5",
+		"--log-level", "debug",
+	}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	want := testStruct{
+		testStructCommon: testStructCommon{Verbose: true},
+
+		Int:      1,
+		Int64:    -42,
+		Uint:     3,
+		Uint64:   4,
+		Float64:  0.5,
+		String:   "from env",
+		Duration: time.Second,
+		LogLevel: "debug",
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("Parse(%v): got = %+v, want = %+v", args, config, want)
+	}
+
+	var names []string
+	for _, flag := range register.Flags() {
+		names = append(names, flag.String())
+	}
+
+	wantNames := []string{
+		"Flag(bool,-v)",
+		"Flag(int,-i/--int)",
+		"Flag(int64/--int64)",
+		"Flag(uint,-u)",
+		"Flag(uint64/--uint64)",
+		"Flag(float64/--ratio)",
+		"Flag(string/--string)",
+		"Flag(time.Duration/--duration)",
+		"Flag(string/--log-level)",
+	}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("Flags(): got = %v, want = %v", names, wantNames)
+	}
+
+	if flag, _ := register.LongFlag("uint64"); !flag.Required() {
+		t.Errorf("Required(): got = false, want = true")
+	}
+}
+
+// TestStructEmbedded is exported, so a nil pointer to it can be allocated.
+type TestStructEmbedded struct {
+	Debug bool
+}
+
+func TestRegisterStruct_embedded_pointer(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		config   struct {
+			*testStructCommon
+			*TestStructEmbedded
+
+			Name string
+		}
+	)
+
+	config.testStructCommon = &testStructCommon{}
+
+	if err := RegisterStruct(&register, &config); err != nil {
+		t.Fatalf("RegisterStruct(): failed to register struct: %s", err)
+	}
+
+	if config.TestStructEmbedded == nil {
+		t.Fatalf("RegisterStruct(): nil embedded pointer must be allocated")
+	}
+
+	args := []string{"-v", "--debug", "--name", "test"}
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if !config.Verbose || !config.Debug || config.Name != "test" {
+		t.Errorf("Parse(%v): got verbose = %v, debug = %v, name = %q, want verbose = %v, debug = %v, name = %q",
+			args, config.Verbose, config.Debug, config.Name, true, true, "test")
+	}
+}
+
+func TestRegisterStruct_required(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		config   testStruct
+	)
+
+	if err := RegisterStruct(&register, &config); err != nil {
+		t.Fatalf("RegisterStruct(): failed to register struct: %s", err)
+	}
+
+	got := parser.Parse(nil, &register, nil)
+	want := &FlagError{Long: "uint64", Err: ErrNotProvided}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(): got error = %q, want error = %q", got, want)
+	}
+}
+
+func TestRegisterStruct_error(t *testing.T) {
+	var num int
+
+	tt := []struct {
+		name string
+		v    interface{}
+		want error
+	}{
+		{
+			name: "nil",
+			v:    nil,
+			want: &StructFieldError{Err: ErrUnsupportedType},
+		},
+		{
+			name: "not a pointer",
+			v:    struct{}{},
+			want: &StructFieldError{Err: ErrUnsupportedType},
+		},
+		{
+			name: "not a struct",
+			v:    &num,
+			want: &StructFieldError{Err: ErrUnsupportedType},
+		},
+		{
+			name: "nil pointer",
+			v:    (*testStruct)(nil),
+			want: &StructFieldError{Err: ErrUnsupportedType},
+		},
+		{
+			name: "unsupported type",
+			v: &struct {
+				Names []string
+			}{},
+			want: &StructFieldError{Field: "Names", Err: ErrUnsupportedType},
+		},
+		{
+			name: "named struct field",
+			v: &struct {
+				testStructCommon
+				Embedded struct{ Int int }
+			}{},
+			want: &StructFieldError{Field: "Embedded", Err: ErrUnsupportedType},
+		},
+		{
+			name: "nil unexported embedded pointer",
+			v: &struct {
+				*testStructCommon
+			}{},
+			want: &StructFieldError{Field: "testStructCommon", Err: ErrNilTarget},
+		},
+		{
+			name: "unknown tag key",
+			v: &struct {
+				Name string `cli:"alias=n"`
+			}{},
+			want: &StructFieldError{Field: "Name", Err: ErrInvalidTag},
+		},
+		{
+			name: "missing tag value",
+			v: &struct {
+				Name string `cli:"long"`
+			}{},
+			want: &StructFieldError{Field: "Name", Err: ErrInvalidTag},
+		},
+		{
+			name: "required with value",
+			v: &struct {
+				Name string `cli:"required=true"`
+			}{},
+			want: &StructFieldError{Field: "Name", Err: ErrInvalidTag},
+		},
+		{
+			name: "invalid name",
+			v: &struct {
+				Name string `cli:"short=nm"`
+			}{},
+			want: &FlagError{Short: "nm", Err: ErrInvalidName},
+		},
+		{
+			name: "broken default",
+			v: &struct {
+				Port int `cli:"default=http"`
+			}{},
+			want: &FlagError{Long: "port", Err: &ParseValueError{Type: "int", Err: ErrSyntax}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var register DefaultRegister
+
+			got := RegisterStruct(&register, tc.v)
+			if !errors.Is(got, tc.want) {
+				t.Fatalf("RegisterStruct(): got error = %q, want error = %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestKebabCase(t *testing.T) {
+	tt := []struct {
+		name string
+		want string
+	}{
+		{name: "Port", want: "port"},
+		{name: "LogLevel", want: "log-level"},
+		{name: "HTTPPort", want: "http-port"},
+		{name: "UserID", want: "user-id"},
+		{name: "V", want: "v"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := kebabCase(tc.name); got != tc.want {
+				t.Errorf("kebabCase(%q): got = %q, want = %q", tc.name, got, tc.want)
+			}
+		})
+	}
+}
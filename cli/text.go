@@ -0,0 +1,72 @@
+//go:build go1.21
+// +build go1.21
+
+package cli
+
+import (
+	"encoding"
+	"reflect"
+)
+
+// encoding.TextUnmarshaler
+
+var (
+	_ Value      = (*textValue)(nil)
+	_ Getter     = (*textValue)(nil)
+	_ Typer      = (*textValue)(nil)
+	_ stringFlag = (*textValue)(nil)
+)
+
+type textValue struct {
+	v encoding.TextUnmarshaler
+}
+
+func newTextValue(v encoding.TextUnmarshaler) Value {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return nil
+	}
+
+	return &textValue{v: v}
+}
+
+func (v *textValue) Set(s string) error {
+	if err := v.v.UnmarshalText([]byte(s)); err != nil {
+		return parseSyntaxError("text", s, err)
+	}
+
+	return nil
+}
+
+func (v *textValue) Get() interface{} { return v.v }
+
+func (v *textValue) String() string {
+	m, ok := v.v.(encoding.TextMarshaler)
+	if !ok {
+		return ""
+	}
+
+	text, err := m.MarshalText()
+	if err != nil {
+		return ""
+	}
+
+	return string(text)
+}
+
+func (*textValue) Type() string { return "text" }
+
+func (*textValue) IsStringFlag() bool { return true }
+
+// TextFlag defines a flag with specified name for a type implementing the
+// encoding.TextUnmarshaler, e.g. *net.IP or *big.Int. The value is stored
+// by v.UnmarshalText, the default value in the help is formatted by
+// MarshalText if v implements the encoding.TextMarshaler.
+//
+//   var limit big.Int
+//   _ = cli.TextFlag(register, "limit", &limit)
+//
+// Options are the same as for the cli.StringVar.
+func TextFlag[T encoding.TextUnmarshaler](register Register, name string, v T, options ...FlagOptionApplyer) error {
+	return Var(register, newTextValue(v), name, options...)
+}
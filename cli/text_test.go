@@ -0,0 +1,112 @@
+//go:build go1.21
+// +build go1.21
+
+package cli
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+// hardwareAddr is a net.HardwareAddr with the encoding.TextUnmarshaler.
+type hardwareAddr net.HardwareAddr
+
+func (a *hardwareAddr) UnmarshalText(text []byte) error {
+	mac, err := net.ParseMAC(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = hardwareAddr(mac)
+	return nil
+}
+
+func (a hardwareAddr) MarshalText() ([]byte, error) {
+	return []byte(net.HardwareAddr(a).String()), nil
+}
+
+func TestTextFlag(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		mac      hardwareAddr
+		ip       net.IP
+	)
+
+	if err := TextFlag(&register, "mac", &mac, WithDefault("00:00:5e:00:53:00")); err != nil {
+		t.Fatalf("TextFlag(): failed to register flag: %s", err)
+	}
+
+	if err := TextFlag(&register, "ip", &ip); err != nil {
+		t.Fatalf("TextFlag(): failed to register flag: %s", err)
+	}
+
+	flag, _ := register.LongFlag("mac")
+	if got, _ := flag.Default(); got != "00:00:5e:00:53:00" {
+		t.Errorf("Default(): got = %q, want = %q", got, "00:00:5e:00:53:00")
+	}
+
+	if got := flag.Type(); got != "text" {
+		t.Errorf("Type(): got = %q, want = %q", got, "text")
+	}
+
+	args := []string{"--mac", "00:00:5e:00:53:01", "--ip", "192.168.1.1"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if got := net.HardwareAddr(mac).String(); got != "00:00:5e:00:53:01" {
+		t.Errorf("Parse(%v): mac: got = %q, want = %q", args, got, "00:00:5e:00:53:01")
+	}
+
+	if !ip.Equal(net.IPv4(192, 168, 1, 1)) {
+		t.Errorf("Parse(%v): ip: got = %v, want = %v", args, ip, net.IPv4(192, 168, 1, 1))
+	}
+
+	if got := flag.Value.String(); got != "00:00:5e:00:53:01" {
+		t.Errorf("String(): got = %q, want = %q", got, "00:00:5e:00:53:01")
+	}
+}
+
+func TestTextFlag_broken_value(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		mac      hardwareAddr
+	)
+
+	_ = TextFlag(&register, "mac", &mac)
+
+	args := []string{"--mac", "not-a-mac"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &FlagError{
+		Long: "mac",
+		Err:  &ParseValueError{Type: "text", Err: ErrSyntax},
+	}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, got, want)
+	}
+
+	var addrErr *net.AddrError
+	if !errors.As(got, &addrErr) {
+		t.Errorf("Parse(%v): got error = %v, want underlying net.AddrError", args, got)
+	}
+
+	var fe *FlagError
+	if !errors.As(got, &fe) || !errors.Is(fe.Err, want.Err) {
+		t.Errorf("Parse(%v): got value error = %v, want = %v", args, got, want.Err)
+	}
+}
+
+func TestTextFlag_nil_target(t *testing.T) {
+	var register DefaultRegister
+
+	got := TextFlag(&register, "mac", (*hardwareAddr)(nil))
+	want := &FlagError{Long: "mac", Err: ErrNilTarget}
+	if !errors.Is(got, want) {
+		t.Errorf("TextFlag(): got error = %v, want error = %v", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"time"
+)
+
+// Layouts for the cli.Time flags.
+const (
+	RFC3339Flag  = time.RFC3339
+	DateFlag     = "2006-01-02"
+	DateTimeFlag = "2006-01-02 15:04:05"
+)
+
+// time.Time
+
+var (
+	_ Value   = (*timeValue)(nil)
+	_ Getter  = (*timeValue)(nil)
+	_ Emptier = (*timeValue)(nil)
+	_ Typer   = (*timeValue)(nil)
+)
+
+type timeValue struct {
+	p      *time.Time
+	layout string
+}
+
+func newTimeValue(p *time.Time, layout string) *timeValue {
+	if p == nil {
+		return nil
+	}
+
+	return &timeValue{p: p, layout: layout}
+}
+
+func (v *timeValue) Set(s string) error {
+	t, err := time.Parse(v.layout, s)
+	if err != nil {
+		return parseSyntaxError("time", s, err)
+	}
+
+	*v.p = t
+	return nil
+}
+
+func (v *timeValue) Get() interface{} { return *v.p }
+
+func (v *timeValue) Empty() bool { return v.p.IsZero() }
+
+func (v *timeValue) String() string {
+	if v.p == nil || v.p.IsZero() {
+		return ""
+	}
+
+	return v.p.Format(v.layout)
+}
+
+func (*timeValue) Type() string { return "time" }
+
+// TimeVar defines a time.Time flag with specified name which is parsed by
+// the time.Parse with the layout (e.g. cli.RFC3339Flag, cli.DateFlag or
+// cli.DateTimeFlag).
+// The argument p points to a time.Time variable in which to store the value of the flag.
+//
+// Options are the same as for the cli.StringVar.
+func TimeVar(register Register, p *time.Time, name string, layout string, options ...FlagOptionApplyer) error {
+	return Var(register, newTimeValue(p, layout), name, options...)
+}
+
+// Time defines a time.Time flag with specified name and layout (see cli.TimeVar).
+// The return value is the address of a time.Time variable that stores the value of the flag.
+func Time(register Register, name string, layout string, options ...FlagOptionApplyer) *time.Time {
+	p := new(time.Time)
+	_ = TimeVar(register, p, name, layout, options...)
+	return p
+}
+
+// TimeArgVar defines a time.Time argument with specified name and layout (see cli.TimeVar).
+// The argument p points to a time.Time variable in which to store the value of the argument.
+func TimeArgVar(register Register, p *time.Time, name string, layout string, options ...ArgOptionApplyer) error {
+	return ArgVar(register, newTimeValue(p, layout), name, options...)
+}
+
+// TimeArg defines a time.Time argument with specified name and layout (see cli.TimeVar).
+// The return value is the address of a time.Time variable that stores the value of the argument.
+func TimeArg(register Register, name string, layout string, options ...ArgOptionApplyer) *time.Time {
+	p := new(time.Time)
+	_ = TimeArgVar(register, p, name, layout, options...)
+	return p
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"net/url"
+)
+
+// *url.URL
+
+var (
+	_ Value   = (*urlValue)(nil)
+	_ Getter  = (*urlValue)(nil)
+	_ Emptier = (*urlValue)(nil)
+	_ Typer   = (*urlValue)(nil)
+)
+
+type urlValue struct {
+	p      **url.URL
+	strict bool // Require a scheme and a host.
+}
+
+func newURLValue(p **url.URL, strict bool) *urlValue {
+	if p == nil {
+		return nil
+	}
+
+	return &urlValue{p: p, strict: strict}
+}
+
+func (v *urlValue) Set(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || (v.strict && (u.Scheme == "" || u.Host == "")) {
+		return &ParseValueError{
+			Type:  "url",
+			Err:   ErrSyntax,
+			Input: s,
+		}
+	}
+
+	*v.p = u
+	return nil
+}
+
+func (v *urlValue) Get() interface{} { return *v.p }
+
+func (v *urlValue) Empty() bool { return *v.p == nil }
+
+func (v *urlValue) String() string {
+	if v.p == nil || *v.p == nil {
+		return ""
+	}
+
+	return (*v.p).String()
+}
+
+func (*urlValue) Type() string { return "url" }
+
+// URLVar defines a *url.URL flag with specified name.
+// The value is parsed by the url.Parse, so relative URLs are accepted too.
+// The argument p points to a *url.URL variable in which to store the value of the flag.
+//
+// Options are the same as for the cli.StringVar.
+func URLVar(register Register, p **url.URL, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newURLValue(p, false), name, options...)
+}
+
+// URL defines a *url.URL flag with specified name (see cli.URLVar).
+// The return value is the address of a *url.URL variable that stores the value of the flag.
+func URL(register Register, name string, options ...FlagOptionApplyer) **url.URL {
+	p := new(*url.URL)
+	_ = URLVar(register, p, name, options...)
+	return p
+}
+
+// URLArgVar defines a *url.URL argument with specified name (see cli.URLVar).
+// The argument p points to a *url.URL variable in which to store the value of the argument.
+func URLArgVar(register Register, p **url.URL, name string, options ...ArgOptionApplyer) error {
+	return ArgVar(register, newURLValue(p, false), name, options...)
+}
+
+// URLArg defines a *url.URL argument with specified name (see cli.URLVar).
+// The return value is the address of a *url.URL variable that stores the value of the argument.
+func URLArg(register Register, name string, options ...ArgOptionApplyer) **url.URL {
+	p := new(*url.URL)
+	_ = URLArgVar(register, p, name, options...)
+	return p
+}
+
+// StrictURLVar defines a *url.URL flag with specified name which requires
+// an absolute URL with a scheme and a host (e.g. "https://example.com").
+// The argument p points to a *url.URL variable in which to store the value of the flag.
+//
+// Options are the same as for the cli.StringVar.
+func StrictURLVar(register Register, p **url.URL, name string, options ...FlagOptionApplyer) error {
+	return Var(register, newURLValue(p, true), name, options...)
+}
+
+// StrictURL defines a *url.URL flag with specified name which requires
+// an absolute URL (see cli.StrictURLVar).
+// The return value is the address of a *url.URL variable that stores the value of the flag.
+func StrictURL(register Register, name string, options ...FlagOptionApplyer) **url.URL {
+	p := new(*url.URL)
+	_ = StrictURLVar(register, p, name, options...)
+	return p
+}
+
+// StrictURLArgVar defines a *url.URL argument with specified name which
+// requires an absolute URL (see cli.StrictURLVar).
+// The argument p points to a *url.URL variable in which to store the value of the argument.
+func StrictURLArgVar(register Register, p **url.URL, name string, options ...ArgOptionApplyer) error {
+	return ArgVar(register, newURLValue(p, true), name, options...)
+}
+
+// StrictURLArg defines a *url.URL argument with specified name which
+// requires an absolute URL (see cli.StrictURLVar).
+// The return value is the address of a *url.URL variable that stores the value of the argument.
+func StrictURLArg(register Register, name string, options ...ArgOptionApplyer) **url.URL {
+	p := new(*url.URL)
+	_ = StrictURLArgVar(register, p, name, options...)
+	return p
+}
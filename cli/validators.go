@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrTooShort = errors.New("too short")
+
+	ErrTooLong = errors.New("too long")
+)
+
+// ValidationError is returned by the Set of a flag or an arg if a validator
+// rejected the value (see WithValidator).
+type ValidationError struct {
+	Flag  string // Name of the flag or the arg.
+	Value string // Raw value rejected by the validator.
+	Err   error
+
+	arg bool // Flag is a name of the arg.
+}
+
+func (e *ValidationError) Error() string {
+	msg := "unknown error"
+	if e.Err != nil {
+		msg = e.Err.Error()
+	}
+
+	// Do not add "cli: " prefix. It's not a top level error.
+	if e.arg {
+		return fmt.Sprintf("validation error: arg '%s': %s", e.Flag, msg)
+	}
+
+	return fmt.Sprintf("validation error: flag '%s': %s", formatFlagName(e.Flag), msg)
+}
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+func (e *ValidationError) Is(err error) bool {
+	pe, ok := err.(*ValidationError)
+	return ok && pe.Flag == e.Flag && pe.Value == e.Value && errors.Is(pe.Err, e.Err)
+}
+
+// IsValidationError reports whether any error in the err's chain is the
+// ValidationError.
+func IsValidationError(err error) bool {
+	var ve *ValidationError
+	return errors.As(err, &ve)
+}
+
+// WithValidator adds a validator of the flag value. The validator is called
+// with a raw value after the successful Value.Set, an error of the validator
+// is wrapped into the ValidationError.
+//
+//   _ = cli.Int(register, "port", cli.WithValidator(func(v string) error {
+//       if port, _ := strconv.Atoi(v); port < 1 || port > 65535 {
+//           return errors.New("port is out of range")
+//       }
+//       return nil
+//   }))
+func WithValidator(fn func(value string) error) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		if fn != nil {
+			o.validators = append(o.validators, fn)
+		}
+	}
+}
+
+// WithArgValidator adds a validator of the arg value (see cli.WithValidator).
+func WithArgValidator(fn func(value string) error) ArgOptionFunc {
+	return func(o *ArgOptions) {
+		if fn != nil {
+			o.validators = append(o.validators, fn)
+		}
+	}
+}
+
+// WithChoices restricts the flag to the allowed set of values. Choices are
+// case-sensitive unless the cli.WithCaseFoldChoices is passed. A value not in
+// the set is rejected before the Set with the ErrChoice wrapped into the
+// ParseValueError. An empty set does not restrict the flag.
+//
+//   _ = cli.String(register, "format", cli.WithChoices("json", "yaml", "text"))
+func WithChoices(values ...string) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.Choices = values
+	}
+}
+
+// WithCaseFoldChoices makes choices of the flag case-insensitive (see
+// cli.WithChoices).
+func WithCaseFoldChoices() FlagOptionFunc {
+	return func(o *FlagOptions) {
+		o.caseFoldChoices = true
+	}
+}
+
+// WithMinLength adds a validator that the value is at least n characters
+// long. A shorter value is rejected with the ErrTooShort wrapped into the
+// ValidationError.
+//
+//   _ = cli.String(register, "name", cli.WithMinLength(1))
+func WithMinLength(n int) FlagOptionFunc {
+	return WithValidator(func(value string) error {
+		if l := utf8.RuneCountInString(value); l < n {
+			return fmt.Errorf("%w: %d characters, want at least %d", ErrTooShort, l, n)
+		}
+
+		return nil
+	})
+}
+
+// WithMaxLength adds a validator that the value is at most n characters
+// long. A longer value is rejected with the ErrTooLong wrapped into the
+// ValidationError.
+//
+//   _ = cli.String(register, "query", cli.WithMaxLength(256))
+func WithMaxLength(n int) FlagOptionFunc {
+	return WithValidator(func(value string) error {
+		if l := utf8.RuneCountInString(value); l > n {
+			return fmt.Errorf("%w: %d characters, want at most %d", ErrTooLong, l, n)
+		}
+
+		return nil
+	})
+}
+
+// WithTransform adds a transformation of the raw flag value, e.g. to
+// normalise values from environment variables. Transformations are applied in
+// order before the Value.Set, choices and validators get the transformed
+// value.
+//
+//   _ = cli.String(register, "format", cli.WithTransform(strings.ToLower))
+func WithTransform(fn func(value string) string) FlagOptionFunc {
+	return func(o *FlagOptions) {
+		if fn != nil {
+			o.transforms = append(o.transforms, fn)
+		}
+	}
+}
+
+var (
+	// TrimSpaceTransform trims leading and trailing white space of the value
+	// (see WithTransform).
+	TrimSpaceTransform = WithTransform(strings.TrimSpace)
+
+	// ToLowerTransform converts the value to lower case (see WithTransform).
+	ToLowerTransform = WithTransform(strings.ToLower)
+
+	// ToUpperTransform converts the value to upper case (see WithTransform).
+	ToUpperTransform = WithTransform(strings.ToUpper)
+)
+
+type transforms []func(value string) string
+
+func (ts transforms) apply(value string) string {
+	for _, transform := range ts {
+		value = transform(value)
+	}
+
+	return value
+}
+
+func choicesCheck(typ string, choices []string, caseFold bool) func(string) error {
+	if typ == "" {
+		typ = "string"
+	}
+
+	return func(value string) error {
+		for _, c := range choices {
+			if c == value || (caseFold && strings.EqualFold(c, value)) {
+				return nil
+			}
+		}
+
+		return &ParseValueError{
+			Type:  typ,
+			Err:   ErrChoice,
+			Input: value,
+		}
+	}
+}
+
+var (
+	_ Value      = (*validatedValue)(nil)
+	_ Getter     = (*validatedValue)(nil)
+	_ Emptier    = (*validatedValue)(nil)
+	_ Typer      = (*validatedValue)(nil)
+	_ stringFlag = (*validatedValue)(nil)
+	_ boolFlag   = (*validatedBoolValue)(nil)
+)
+
+type validators []func(value string) error
+
+// validatedValue calls validators after every successful Set of the value.
+type validatedValue struct {
+	value      Value
+	name       string
+	arg        bool
+	transforms transforms               // Applied to the raw value first.
+	check      func(value string) error // Called before the Set, its error is not wrapped.
+	validators validators
+}
+
+func newValidatedValue(value Value, name string, arg bool, transforms transforms, check func(string) error, validators validators) Value {
+	if len(transforms) == 0 && check == nil && len(validators) == 0 {
+		return value
+	}
+
+	v := &validatedValue{
+		value:      value,
+		name:       name,
+		arg:        arg,
+		transforms: transforms,
+		check:      check,
+		validators: validators,
+	}
+
+	// Parser checks only the presence of the IsBoolFlag for some cases.
+	if _, ok := value.(boolFlag); ok {
+		return &validatedBoolValue{v}
+	}
+
+	return v
+}
+
+func (v *validatedValue) Set(val string) error {
+	val = v.transforms.apply(val)
+
+	if v.check != nil {
+		if err := v.check(val); err != nil {
+			return err
+		}
+	}
+
+	if err := v.value.Set(val); err != nil {
+		return err
+	}
+
+	for _, validate := range v.validators {
+		if err := validate(val); err != nil {
+			return &ValidationError{Flag: v.name, Value: val, Err: err, arg: v.arg}
+		}
+	}
+
+	return nil
+}
+
+func (v *validatedValue) String() string { return v.value.String() }
+
+func (v *validatedValue) Get() interface{} {
+	if g, ok := v.value.(Getter); ok {
+		return g.Get()
+	}
+
+	return nil
+}
+
+func (v *validatedValue) Empty() bool {
+	if ev, ok := v.value.(Emptier); ok {
+		return ev.Empty()
+	}
+
+	return v.value.String() == ""
+}
+
+func (v *validatedValue) Type() string {
+	if t, ok := v.value.(Typer); ok {
+		return t.Type()
+	}
+
+	return ""
+}
+
+func (v *validatedValue) Default() string { return defaultString(v.value) }
+
+func (v *validatedValue) IsStringFlag() bool {
+	fv, ok := v.value.(stringFlag)
+	return ok && fv.IsStringFlag()
+}
+
+type validatedBoolValue struct {
+	*validatedValue
+}
+
+func (v *validatedBoolValue) IsBoolFlag() bool {
+	return v.value.(boolFlag).IsBoolFlag()
+}
@@ -0,0 +1,395 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+var errPortRange = errors.New("port is out of range")
+
+func validatePort(v string) error {
+	port, _ := strconv.Atoi(v)
+	if port < 1 || port > 65535 {
+		return errPortRange
+	}
+
+	return nil
+}
+
+func TestWithValidator(t *testing.T) {
+	tt := []struct {
+		name    string
+		args    []string
+		want    int
+		wantErr error
+	}{
+		{
+			name: "valid",
+			args: []string{"--port", "8080"},
+			want: 8080,
+		},
+		{
+			name: "invalid",
+			args: []string{"--port", "70000"},
+			want: 70000,
+			wantErr: &FlagError{
+				Long: "port",
+				Err:  &ValidationError{Flag: "port", Value: "70000", Err: errPortRange},
+			},
+		},
+		{
+			name: "broken value",
+			args: []string{"--port", "http"},
+			wantErr: &FlagError{
+				Long: "port",
+				Err:  &ParseValueError{Type: "int", Err: ErrSyntax},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			port := Int(&register, "port", WithValidator(validatePort))
+
+			err := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+
+			if *port != tc.want {
+				t.Errorf("Parse(%v): got = %d, want = %d", tc.args, *port, tc.want)
+			}
+		})
+	}
+}
+
+type portError struct {
+	port int
+}
+
+func (e *portError) Error() string { return fmt.Sprintf("port %d is reserved", e.port) }
+
+func TestWithValidator_custom_error(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = Int(&register, "port", WithValidator(func(v string) error {
+		port, _ := strconv.Atoi(v)
+		if port < 1024 {
+			return &portError{port: port}
+		}
+		return nil
+	}))
+
+	args := []string{"--port", "80"}
+
+	err := parser.Parse(nil, &register, args)
+	if !IsValidationError(err) {
+		t.Fatalf("Parse(%v): got error = %v, want validation error", args, err)
+	}
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Parse(%v): got error = %v, want validation error", args, err)
+	}
+
+	if ve.Flag != "port" || ve.Value != "80" {
+		t.Errorf("Parse(%v): got flag = %q, value = %q, want flag = %q, value = %q", args, ve.Flag, ve.Value, "port", "80")
+	}
+
+	const wantMsg = "validation error: flag '--port': port 80 is reserved"
+	if ve.Error() != wantMsg {
+		t.Errorf("Error(): got = %q, want = %q", ve.Error(), wantMsg)
+	}
+
+	var pe *portError
+	if !errors.As(err, &pe) || pe.port != 80 {
+		t.Errorf("Parse(%v): got error = %v, want port error", args, err)
+	}
+
+	if IsValidationError(&ParseValueError{Type: "int", Err: ErrSyntax}) {
+		t.Errorf("IsValidationError(): expected false for not a validation error")
+	}
+}
+
+func TestWithValidator_bool(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		calls    []string
+	)
+
+	verbose := Bool(&register, "v", WithValidator(func(v string) error {
+		calls = append(calls, v)
+		return nil
+	}))
+	name := String(&register, "n")
+
+	// Bool flags with validators still don't require a value.
+	args := []string{"-v", "-n", "test"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if !*verbose || *name != "test" {
+		t.Errorf("Parse(%v): got = (%v, %q), want = (%v, %q)", args, *verbose, *name, true, "test")
+	}
+
+	if len(calls) != 1 {
+		t.Errorf("Parse(%v): got %d validator calls, want %d", args, len(calls), 1)
+	}
+}
+
+func TestWithArgValidator(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	_ = IntArg(&register, "port", WithArgValidator(validatePort))
+
+	args := []string{"0"}
+
+	got := parser.Parse(nil, &register, args)
+	want := &ArgError{
+		Name: "port",
+		Err:  &ValidationError{Flag: "port", Value: "0", Err: errPortRange},
+	}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, got, want)
+	}
+}
+
+func TestWithChoices(t *testing.T) {
+	tt := []struct {
+		name     string
+		options  []FlagOptionApplyer
+		value    string
+		wantErr  bool
+		wantFlag []string
+	}{
+		{
+			name:    "empty set",
+			options: []FlagOptionApplyer{WithChoices()},
+			value:   "anything",
+		},
+		{
+			name:     "single element set",
+			options:  []FlagOptionApplyer{WithChoices("json")},
+			value:    "json",
+			wantFlag: []string{"json"},
+		},
+		{
+			name:     "single element set invalid",
+			options:  []FlagOptionApplyer{WithChoices("json")},
+			value:    "yaml",
+			wantErr:  true,
+			wantFlag: []string{"json"},
+		},
+		{
+			name:     "multi element set",
+			options:  []FlagOptionApplyer{WithChoices("json", "yaml", "text")},
+			value:    "yaml",
+			wantFlag: []string{"json", "yaml", "text"},
+		},
+		{
+			name:     "multi element set invalid",
+			options:  []FlagOptionApplyer{WithChoices("json", "yaml", "text")},
+			value:    "xml",
+			wantErr:  true,
+			wantFlag: []string{"json", "yaml", "text"},
+		},
+		{
+			name:     "case-sensitive",
+			options:  []FlagOptionApplyer{WithChoices("json", "yaml")},
+			value:    "JSON",
+			wantErr:  true,
+			wantFlag: []string{"json", "yaml"},
+		},
+		{
+			name:     "case fold",
+			options:  []FlagOptionApplyer{WithCaseFoldChoices(), WithChoices("json", "yaml")},
+			value:    "JSON",
+			wantFlag: []string{"json", "yaml"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			_ = String(&register, "format", tc.options...)
+
+			args := []string{"--format", tc.value}
+
+			var wantErr error
+			if tc.wantErr {
+				wantErr = &FlagError{
+					Long: "format",
+					Err:  &ParseValueError{Type: "string", Err: ErrChoice, Input: tc.value},
+				}
+			}
+
+			if err := parser.Parse(nil, &register, args); !errors.Is(err, wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", args, err, wantErr)
+			}
+
+			flag, _ := register.LongFlag("format")
+			if !reflect.DeepEqual(flag.Choices, tc.wantFlag) && len(flag.Choices)+len(tc.wantFlag) > 0 {
+				t.Errorf("Choices: got = %v, want = %v", flag.Choices, tc.wantFlag)
+			}
+		})
+	}
+}
+
+func TestWithLength(t *testing.T) {
+	tt := []struct {
+		name    string
+		options []FlagOptionApplyer
+		value   string
+		wantErr error
+	}{
+		{
+			name:    "min 0 empty",
+			options: []FlagOptionApplyer{WithMinLength(0)},
+			value:   "",
+		},
+		{
+			name:    "min 1 empty",
+			options: []FlagOptionApplyer{WithMinLength(1)},
+			value:   "",
+			wantErr: ErrTooShort,
+		},
+		{
+			name:    "min 1",
+			options: []FlagOptionApplyer{WithMinLength(1)},
+			value:   "a",
+		},
+		{
+			name:    "max 5",
+			options: []FlagOptionApplyer{WithMaxLength(5)},
+			value:   "hello",
+		},
+		{
+			name:    "max 5 six chars",
+			options: []FlagOptionApplyer{WithMaxLength(5)},
+			value:   "hello!",
+			wantErr: ErrTooLong,
+		},
+		{
+			name:    "max 5 runes",
+			options: []FlagOptionApplyer{WithMaxLength(5)},
+			value:   "привет",
+			wantErr: ErrTooLong,
+		},
+		{
+			name:    "with choices",
+			options: []FlagOptionApplyer{WithChoices("a", "abcdef"), WithMaxLength(5)},
+			value:   "abcdef",
+			wantErr: ErrTooLong,
+		},
+		{
+			name:    "with choices not in choices",
+			options: []FlagOptionApplyer{WithChoices("a", "abcdef"), WithMaxLength(5)},
+			value:   "abc",
+			wantErr: ErrChoice,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			_ = String(&register, "name", tc.options...)
+
+			args := []string{"--name=" + tc.value}
+
+			err := parser.Parse(nil, &register, args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", args, err, tc.wantErr)
+			}
+
+			if tc.wantErr != nil && tc.wantErr != ErrChoice && !IsValidationError(err) {
+				t.Errorf("Parse(%v): got error = %v, want validation error", args, err)
+			}
+		})
+	}
+}
+
+func TestWithTransform(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	port := Int(&register, "port", TrimSpaceTransform)
+	name := String(&register, "name", TrimSpaceTransform, ToUpperTransform)
+	format := String(&register, "format", ToLowerTransform, WithChoices("json", "yaml"))
+
+	args := []string{"--port", " 8080 ", "--name", "  admin\t", "--format", "JSON"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if *port != 8080 {
+		t.Errorf("Parse(%v): port: got = %d, want = %d", args, *port, 8080)
+	}
+
+	if *name != "ADMIN" {
+		t.Errorf("Parse(%v): name: got = %q, want = %q", args, *name, "ADMIN")
+	}
+
+	if *format != "json" {
+		t.Errorf("Parse(%v): format: got = %q, want = %q", args, *format, "json")
+	}
+}
+
+func TestWithTransform_validator(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		calls    []string
+	)
+
+	port := Int(&register, "port", TrimSpaceTransform, WithValidator(func(v string) error {
+		calls = append(calls, v)
+		return validatePort(v)
+	}))
+
+	args := []string{"--port", " 70000 "}
+
+	got := parser.Parse(nil, &register, args)
+	want := &FlagError{
+		Long: "port",
+		Err:  &ValidationError{Flag: "port", Value: "70000", Err: errPortRange},
+	}
+	if !errors.Is(got, want) {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, got, want)
+	}
+
+	if !reflect.DeepEqual(calls, []string{"70000"}) {
+		t.Errorf("Parse(%v): validator calls: got = %q, want = %q", args, calls, []string{"70000"})
+	}
+
+	if *port != 70000 {
+		t.Errorf("Parse(%v): port: got = %d, want = %d", args, *port, 70000)
+	}
+}
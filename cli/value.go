@@ -3,6 +3,7 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"time"
 )
@@ -11,16 +12,26 @@ var (
 	ErrSyntax = errors.New("invalid syntax")
 
 	ErrRange = errors.New("value out of range")
+
+	ErrChoice = errors.New("invalid choice")
 )
 
 type ParseValueError struct {
-	Type string
-	Err  error
+	Type  string
+	Err   error
+	Input string // Raw value which failed to parse.
+	Flag  string // Long or short name of the flag. Filled by the parser.
+
+	// wrapped is the Err with the underlying error of a parser (see
+	// syntaxError). It keeps the Err comparable.
+	wrapped error
 }
 
 func (e *ParseValueError) Error() string {
 	msg := "unknown error"
-	if e.Err != nil {
+	if e.wrapped != nil {
+		msg = e.wrapped.Error()
+	} else if e.Err != nil {
 		msg = e.Err.Error()
 	}
 
@@ -28,14 +39,43 @@ func (e *ParseValueError) Error() string {
 	return fmt.Sprintf("parse %s error: %s", e.Type, msg)
 }
 
-func (e *ParseValueError) Unwrap() error { return e.Err }
+func (e *ParseValueError) Unwrap() error {
+	if e.wrapped != nil {
+		return e.wrapped
+	}
+
+	return e.Err
+}
 
 func (e *ParseValueError) Is(err error) bool {
 	pe, ok := err.(*ParseValueError)
 	return ok && pe.Type == e.Type && errors.Is(pe.Err, e.Err)
 }
 
-func numError(typ string, err error) error {
+// syntaxError is an ErrSyntax with the underlying error of a parser.
+type syntaxError struct {
+	err error
+}
+
+func (e *syntaxError) Error() string { return ErrSyntax.Error() + ": " + e.err.Error() }
+
+func (e *syntaxError) Unwrap() error { return e.err }
+
+func (e *syntaxError) Is(err error) bool { return err == ErrSyntax }
+
+// parseSyntaxError returns an ErrSyntax of the typ which wraps the err of a
+// parser, so both errors.Is with the ErrSyntax and errors.As with the err
+// work.
+func parseSyntaxError(typ, input string, err error) *ParseValueError {
+	return &ParseValueError{
+		Type:    typ,
+		Err:     ErrSyntax,
+		Input:   input,
+		wrapped: &syntaxError{err: err},
+	}
+}
+
+func numError(typ, input string, err error) error {
 	ne, ok := err.(*strconv.NumError)
 	if ok {
 		if ne.Err == strconv.ErrSyntax {
@@ -46,8 +86,26 @@ func numError(typ string, err error) error {
 	}
 
 	return &ParseValueError{
-		Type: typ,
-		Err:  err,
+		Type:  typ,
+		Err:   err,
+		Input: input,
+	}
+}
+
+// isNilValue reports whether the value is nil or points to nothing, e.g. a
+// value of the cli.IntVar with a nil pointer.
+func isNilValue(v Value) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Func, reflect.Map, reflect.Chan, reflect.Interface:
+		return rv.IsNil()
+
+	default:
+		return false
 	}
 }
 
@@ -75,14 +133,31 @@ type Typer interface {
 	Type() string
 }
 
+// Defaulter is implemented by values which show their default values in the
+// help not like the String does.
+type Defaulter interface {
+	Value
+	Default() string
+}
+
+// defaultString returns a representation of the current value as a default.
+func defaultString(v Value) string {
+	if d, ok := v.(Defaulter); ok {
+		return d.Default()
+	}
+
+	return v.String()
+}
+
 // bool
 
 func (b *boolValue) Set(s string) error {
 	v, err := parseBool(s)
 	if err != nil {
 		err = &ParseValueError{
-			Type: "bool",
-			Err:  err,
+			Type:  "bool",
+			Err:   err,
+			Input: s,
 		}
 	}
 	*b = boolValue(v)
@@ -176,7 +251,7 @@ func parseBool(str string) (bool, error) {
 func (u *uint8Value) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, 8)
 	if err != nil {
-		err = numError("uint8", err)
+		err = numError("uint8", s, err)
 	}
 	*u = uint8Value(v)
 	return err
@@ -187,7 +262,7 @@ func (u *uint8Value) Set(s string) error {
 func (u *uint16Value) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, 16)
 	if err != nil {
-		err = numError("uint16", err)
+		err = numError("uint16", s, err)
 	}
 	*u = uint16Value(v)
 	return err
@@ -198,7 +273,7 @@ func (u *uint16Value) Set(s string) error {
 func (u *uint32Value) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, 32)
 	if err != nil {
-		err = numError("uint32", err)
+		err = numError("uint32", s, err)
 	}
 	*u = uint32Value(v)
 	return err
@@ -209,7 +284,7 @@ func (u *uint32Value) Set(s string) error {
 func (u *uint64Value) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, 64)
 	if err != nil {
-		err = numError("uint64", err)
+		err = numError("uint64", s, err)
 	}
 	*u = uint64Value(v)
 	return err
@@ -220,7 +295,7 @@ func (u *uint64Value) Set(s string) error {
 func (i *int8Value) Set(s string) error {
 	v, err := strconv.ParseInt(s, 0, 8)
 	if err != nil {
-		err = numError("int8", err)
+		err = numError("int8", s, err)
 	}
 	*i = int8Value(v)
 	return err
@@ -231,7 +306,7 @@ func (i *int8Value) Set(s string) error {
 func (i *int16Value) Set(s string) error {
 	v, err := strconv.ParseInt(s, 0, 16)
 	if err != nil {
-		err = numError("int16", err)
+		err = numError("int16", s, err)
 	}
 	*i = int16Value(v)
 	return err
@@ -242,7 +317,7 @@ func (i *int16Value) Set(s string) error {
 func (i *int32Value) Set(s string) error {
 	v, err := strconv.ParseInt(s, 0, 32)
 	if err != nil {
-		err = numError("int32", err)
+		err = numError("int32", s, err)
 	}
 	*i = int32Value(v)
 	return err
@@ -253,7 +328,7 @@ func (i *int32Value) Set(s string) error {
 func (i *int64Value) Set(s string) error {
 	v, err := strconv.ParseInt(s, 0, 64)
 	if err != nil {
-		err = numError("int64", err)
+		err = numError("int64", s, err)
 	}
 	*i = int64Value(v)
 	return err
@@ -264,7 +339,7 @@ func (i *int64Value) Set(s string) error {
 func (i *float32Value) Set(s string) error {
 	v, err := strconv.ParseFloat(s, 32)
 	if err != nil {
-		err = numError("float32", err)
+		err = numError("float32", s, err)
 	}
 	*i = float32Value(v)
 	return err
@@ -275,7 +350,7 @@ func (i *float32Value) Set(s string) error {
 func (i *float64Value) Set(s string) error {
 	v, err := strconv.ParseFloat(s, 64)
 	if err != nil {
-		err = numError("float64", err)
+		err = numError("float64", s, err)
 	}
 	*i = float64Value(v)
 	return err
@@ -300,7 +375,7 @@ type stringFlag interface {
 func (i *intValue) Set(s string) error {
 	v, err := strconv.ParseInt(s, 0, strconv.IntSize)
 	if err != nil {
-		err = numError("int", err)
+		err = numError("int", s, err)
 	}
 	*i = intValue(v)
 	return err
@@ -311,7 +386,7 @@ func (i *intValue) Set(s string) error {
 func (u *uintValue) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, strconv.IntSize)
 	if err != nil {
-		err = numError("uint", err)
+		err = numError("uint", s, err)
 	}
 	*u = uintValue(v)
 	return err
@@ -323,8 +398,9 @@ func (d *timeDurationValue) Set(s string) error {
 	v, err := time.ParseDuration(s)
 	if err != nil {
 		err = &ParseValueError{
-			Type: "time.Duration",
-			Err:  ErrSyntax,
+			Type:  "time.Duration",
+			Err:   ErrSyntax,
+			Input: s,
 		}
 	}
 
@@ -3,7 +3,18 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestIsBoolValue(t *testing.T) {
@@ -395,3 +406,907 @@ func TestParseValueError_As(t *testing.T) {
 		t.Errorf("got = %#v, want = %#v", got, want)
 	}
 }
+
+func TestParseValueError_Input(t *testing.T) {
+	var (
+		b  bool
+		i  int
+		u8 uint8
+		f  float64
+		d  time.Duration
+		sc int
+		c  int
+		bs int64
+		ip net.IP
+		m  map[string]string
+		tm time.Time
+		u  *url.URL
+		re *regexp.Regexp
+	)
+
+	tt := []struct {
+		name  string
+		value Value
+		input string
+	}{
+		{name: "bool", value: newBoolValue(&b), input: "yep"},
+		{name: "int", value: newIntValue(&i), input: "abcd"},
+		{name: "uint8", value: newUint8Value(&u8), input: "256"},
+		{name: "float64", value: newFloat64Value(&f), input: "1.2.3"},
+		{name: "time.Duration", value: newDurationValue(&d), input: "1 hour"},
+		{name: "scaledint", value: (*scaledIntValue)(&sc), input: "10x"},
+		{name: "count", value: (*countValue)(&c), input: "-1"},
+		{name: "bytesize", value: (*byteSizeValue)(&bs), input: "10XB"},
+		{name: "ip", value: (*ipValue)(&ip), input: "300.0.0.1"},
+		{name: "stringmap", value: (*stringMapValue)(&m), input: "key"},
+		{name: "time", value: &timeValue{p: &tm, layout: DateFlag}, input: "01/02/2006"},
+		{name: "url", value: &urlValue{p: &u, strict: true}, input: "example"},
+		{name: "regexp", value: &regexpValue{p: &re}, input: "(a"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.value.Set(tc.input)
+
+			var pe *ParseValueError
+			if !errors.As(err, &pe) {
+				t.Fatalf("Set(%q): got error = %v, want ParseValueError", tc.input, err)
+			}
+
+			if pe.Input != tc.input {
+				t.Errorf("Set(%q): Input: got = %q, want = %q", tc.input, pe.Input, tc.input)
+			}
+		})
+	}
+}
+
+func TestScaledIntValue_Set(t *testing.T) {
+	tt := []struct {
+		value string
+		want  int
+	}{
+		{value: "0", want: 0},
+		{value: "1337", want: 1337},
+		{value: "-7", want: -7},
+		{value: "10K", want: 10000},
+		{value: "10k", want: 10000},
+		{value: "5M", want: 5000000},
+		{value: "5m", want: 5000000},
+		{value: "2G", want: 2000000000},
+		{value: "2g", want: 2000000000},
+		{value: "-3k", want: -3000},
+		{value: "0x10K", want: 16000},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.value, func(t *testing.T) {
+			var got int
+			if err := newScaledIntValue(&got).Set(tc.value); err != nil {
+				t.Fatalf("Set(%q): failed to set the value: %s", tc.value, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("Set(%q): got = %v, want = %v", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScaledIntValue_Set_broken_value(t *testing.T) {
+	tt := []struct {
+		name  string
+		value string
+		want  error
+	}{
+		{"empty", "", &ParseValueError{Type: "scaledint", Err: ErrSyntax}},
+		{"only suffix", "K", &ParseValueError{Type: "scaledint", Err: ErrSyntax}},
+		{"unknown suffix", "10X", &ParseValueError{Type: "scaledint", Err: ErrSyntax}},
+		{"binary suffix", "10Ki", &ParseValueError{Type: "scaledint", Err: ErrSyntax}},
+		{"float", "1.5K", &ParseValueError{Type: "scaledint", Err: ErrSyntax}},
+		{"int max overflow", intMaxOverflowValue(), &ParseValueError{Type: "scaledint", Err: ErrRange}},
+		{"scaled max overflow", strconv.Itoa(maxInt/1000+1) + "K", &ParseValueError{Type: "scaledint", Err: ErrRange}},
+		{"scaled min overflow", strconv.Itoa(minInt/1000-1) + "K", &ParseValueError{Type: "scaledint", Err: ErrRange}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var v int
+			got := newScaledIntValue(&v).Set(tc.value)
+			if !errors.Is(got, tc.want) {
+				t.Fatalf("Set(%q): got error = %q, want error = %q", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScaledInt(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	count := ScaledInt(&register, "count")
+	limit := ScaledIntArg(&register, "limit")
+
+	if err := parser.Parse(nil, &register, []string{"--count", "10K", "5M"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if *count != 10000 {
+		t.Errorf("Parse(): count: got = %v, want = %v", *count, 10000)
+	}
+
+	if *limit != 5000000 {
+		t.Errorf("Parse(): limit: got = %v, want = %v", *limit, 5000000)
+	}
+}
+
+func TestCountValue_Set(t *testing.T) {
+	tt := []struct {
+		name   string
+		values []string
+		want   int
+	}{
+		{name: "increment", values: []string{"", "true", ""}, want: 3},
+		{name: "reset", values: []string{"", "", "false"}, want: 0},
+		{name: "set", values: []string{"", "5"}, want: 5},
+		{name: "increment after set", values: []string{"5", "true"}, want: 6},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var n int
+			v := newCountValue(&n)
+
+			for _, s := range tc.values {
+				if err := v.Set(s); err != nil {
+					t.Fatalf("Set(%q): failed to set value: %s", s, err)
+				}
+			}
+
+			if n != tc.want {
+				t.Errorf("Set(%q): got = %v, want = %v", tc.values, n, tc.want)
+			}
+		})
+	}
+}
+
+func TestCountValue_Set_broken_value(t *testing.T) {
+	for _, s := range []string{"-1", "many"} {
+		var n int
+		err := newCountValue(&n).Set(s)
+
+		if !errors.Is(err, &ParseValueError{Type: "count", Err: ErrRange}) &&
+			!errors.Is(err, &ParseValueError{Type: "count", Err: ErrSyntax}) {
+			t.Errorf("Set(%q): got error = %v, want ParseValueError", s, err)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	tt := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "unset", args: nil, want: 0},
+		{name: "separate", args: []string{"-v", "-v", "-v"}, want: 3},
+		{name: "posix style", args: []string{"-vvv"}, want: 3},
+		{name: "long", args: []string{"--verbose", "--verbose"}, want: 2},
+		{name: "value", args: []string{"--verbose=5"}, want: 5},
+		{name: "reset", args: []string{"-vv", "--verbose=false"}, want: 0},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			verbose := Count(&register, "verbose", WithShort("v"))
+			name := StringArg(&register, "name", WithOptional())
+
+			args := append(tc.args, "test")
+
+			if err := parser.Parse(nil, &register, args); err != nil {
+				t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+			}
+
+			if *verbose != tc.want {
+				t.Errorf("Parse(%v): got = %v, want = %v", args, *verbose, tc.want)
+			}
+
+			if *name != "test" {
+				t.Errorf("Parse(%v): got name = %q, want = %q", args, *name, "test")
+			}
+		})
+	}
+}
+
+func TestURLValue_Set(t *testing.T) {
+	tt := []struct {
+		name     string
+		value    string
+		strict   bool
+		want     string
+		wantErr  bool
+		wantHost string
+	}{
+		{name: "absolute", value: "https://example.com/path?q=1", want: "https://example.com/path?q=1", wantHost: "example.com"},
+		{name: "relative", value: "../path/to", want: "../path/to"},
+		{name: "malformed", value: "http://[::1", wantErr: true},
+		{name: "malformed escape", value: "%zz", wantErr: true},
+		{name: "strict absolute", value: "https://example.com", strict: true, want: "https://example.com", wantHost: "example.com"},
+		{name: "strict relative", value: "/path/to", strict: true, wantErr: true},
+		{name: "strict without host", value: "mailto:user@example.com", strict: true, wantErr: true},
+		{name: "strict without scheme", value: "//example.com", strict: true, wantErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var u *url.URL
+			v := newURLValue(&u, tc.strict)
+
+			err := v.Set(tc.value)
+			if tc.wantErr {
+				want := &ParseValueError{Type: "url", Err: ErrSyntax}
+				if !errors.Is(err, want) {
+					t.Fatalf("Set(%q): got error = %v, want error = %v", tc.value, err, want)
+				}
+
+				if u != nil {
+					t.Errorf("Set(%q): got = %v, want = nil", tc.value, u)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Set(%q): failed to set value: %s", tc.value, err)
+			}
+
+			if got := v.String(); got != tc.want {
+				t.Errorf("String(): got = %q, want = %q", got, tc.want)
+			}
+
+			if u.Host != tc.wantHost {
+				t.Errorf("Set(%q): got host = %q, want = %q", tc.value, u.Host, tc.wantHost)
+			}
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	endpoint := StrictURL(&register, "endpoint")
+	proxy := URL(&register, "proxy")
+	target := URLArg(&register, "target")
+
+	args := []string{"--endpoint", "https://example.com", "/relative"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if *endpoint == nil || (*endpoint).String() != "https://example.com" {
+		t.Errorf("Parse(%v): endpoint: got = %v, want = %q", args, *endpoint, "https://example.com")
+	}
+
+	if *proxy != nil {
+		t.Errorf("Parse(%v): proxy: got = %v, want = nil", args, *proxy)
+	}
+
+	if *target == nil || (*target).Path != "/relative" {
+		t.Errorf("Parse(%v): target: got = %v, want = %q", args, *target, "/relative")
+	}
+}
+
+func TestIPValue_Set(t *testing.T) {
+	tt := []struct {
+		name    string
+		value   string
+		want    string
+		wantV4  bool
+		wantErr bool
+	}{
+		{name: "ipv4", value: "192.168.1.1", want: "192.168.1.1", wantV4: true},
+		{name: "ipv6", value: "::1", want: "::1"},
+		{name: "ipv6 full", value: "2001:0db8:0000:0000:0000:0000:0000:0001", want: "2001:db8::1"},
+		{name: "ipv4 out of range", value: "256.0.0.1", wantErr: true},
+		{name: "hostname", value: "localhost", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var ip net.IP
+			v := newIPValue(&ip)
+
+			err := v.Set(tc.value)
+			if tc.wantErr {
+				want := &ParseValueError{Type: "ip", Err: ErrSyntax}
+				if !errors.Is(err, want) {
+					t.Fatalf("Set(%q): got error = %v, want error = %v", tc.value, err, want)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Set(%q): failed to set value: %s", tc.value, err)
+			}
+
+			if got := v.String(); got != tc.want {
+				t.Errorf("String(): got = %q, want = %q", got, tc.want)
+			}
+
+			if got := ip.To4() != nil; got != tc.wantV4 {
+				t.Errorf("Set(%q): got IPv4 = %v, want = %v", tc.value, got, tc.wantV4)
+			}
+		})
+	}
+}
+
+func TestCIDRValue_Set(t *testing.T) {
+	tt := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "ipv4", value: "10.0.0.0/24", want: "10.0.0.0/24"},
+		{name: "ipv4 host", value: "10.0.0.1/24", want: "10.0.0.0/24"},
+		{name: "ipv6", value: "2001:db8::/32", want: "2001:db8::/32"},
+		{name: "missing mask", value: "10.0.0.0", wantErr: true},
+		{name: "broken mask", value: "10.0.0.0/33", wantErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var ipnet net.IPNet
+			v := newCIDRValue(&ipnet)
+
+			err := v.Set(tc.value)
+			if tc.wantErr {
+				want := &ParseValueError{Type: "cidr", Err: ErrSyntax}
+				if !errors.Is(err, want) {
+					t.Fatalf("Set(%q): got error = %v, want error = %v", tc.value, err, want)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Set(%q): failed to set value: %s", tc.value, err)
+			}
+
+			if got := v.String(); got != tc.want {
+				t.Errorf("String(): got = %q, want = %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIP(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	bind := IP(&register, "bind")
+	subnet := CIDR(&register, "subnet")
+	target := IPArg(&register, "target")
+
+	args := []string{"--bind", "::1", "--subnet", "10.0.0.0/24", "192.168.1.1"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if !bind.Equal(net.IPv6loopback) {
+		t.Errorf("Parse(%v): bind: got = %v, want = %v", args, *bind, net.IPv6loopback)
+	}
+
+	if subnet.String() != "10.0.0.0/24" {
+		t.Errorf("Parse(%v): subnet: got = %v, want = %v", args, subnet, "10.0.0.0/24")
+	}
+
+	if !target.Equal(net.IPv4(192, 168, 1, 1)) {
+		t.Errorf("Parse(%v): target: got = %v, want = %v", args, *target, "192.168.1.1")
+	}
+}
+
+func TestRegexpValue_Set(t *testing.T) {
+	tt := []struct {
+		name    string
+		value   string
+		posix   bool
+		match   string
+		want    string
+		wantErr bool
+	}{
+		{name: "valid", value: `^v\d+\.\d+$`, match: "v1.2", want: `^v\d+\.\d+$`},
+		{name: "empty", value: "", match: "anything", want: ""},
+		{name: "unclosed group", value: "(abc", wantErr: true},
+		{name: "invalid repeat", value: "*abc", wantErr: true},
+		{name: "posix", value: "a+|a+b", posix: true, match: "aab", want: "a+|a+b"},
+		{name: "posix without perl classes", value: `\d+`, posix: true, wantErr: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var re *regexp.Regexp
+			v := newRegexpValue(&re, tc.posix)
+
+			err := v.Set(tc.value)
+			if tc.wantErr {
+				want := &ParseValueError{Type: "regexp", Err: ErrSyntax}
+				if !errors.Is(err, want) {
+					t.Fatalf("Set(%q): got error = %v, want error = %v", tc.value, err, want)
+				}
+
+				if !errors.Is(err, ErrSyntax) {
+					t.Errorf("Set(%q): got error = %v, want it to wrap %v", tc.value, err, ErrSyntax)
+				}
+
+				var serr *syntax.Error
+				if !errors.As(err, &serr) {
+					t.Errorf("Set(%q): got error = %v, want it to wrap *syntax.Error", tc.value, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Set(%q): failed to set value: %s", tc.value, err)
+			}
+
+			if !re.MatchString(tc.match) {
+				t.Errorf("MatchString(%q): got = false, want = true", tc.match)
+			}
+
+			// Round-trip.
+			if got := v.String(); got != tc.want {
+				t.Errorf("String(): got = %q, want = %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegexpValue_Set_posix_leftmost_longest(t *testing.T) {
+	var re *regexp.Regexp
+	if err := newRegexpValue(&re, true).Set("a+|a+b"); err != nil {
+		t.Fatalf("Set(): failed to set value: %s", err)
+	}
+
+	if got, want := re.FindString("aab"), "aab"; got != want {
+		t.Errorf("FindString(): got = %q, want = %q", got, want)
+	}
+}
+
+func TestRegexp(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	include := Regexp(&register, "include")
+	pattern := RegexpArg(&register, "pattern")
+
+	args := []string{"--include", `\.go$`, "^func"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if !(*include).MatchString("main.go") {
+		t.Errorf("Parse(%v): include: got = %v, want to match %q", args, *include, "main.go")
+	}
+
+	if !(*pattern).MatchString("func main() {}") {
+		t.Errorf("Parse(%v): pattern: got = %v, want to match %q", args, *pattern, "func main() {}")
+	}
+}
+
+func TestTimeValue_Set(t *testing.T) {
+	tt := []struct {
+		name    string
+		layout  string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:   "rfc3339",
+			layout: RFC3339Flag,
+			value:  "2021-03-04T05:06:07Z",
+			want:   time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		},
+		{
+			name:   "date",
+			layout: DateFlag,
+			value:  "2021-03-04",
+			want:   time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "date time",
+			layout: DateTimeFlag,
+			value:  "2021-03-04 05:06:07",
+			want:   time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		},
+		{
+			name:   "custom",
+			layout: "02/01/2006",
+			value:  "04/03/2021",
+			want:   time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "wrong layout",
+			layout:  DateFlag,
+			value:   "2021-03-04 05:06:07",
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			layout:  DateFlag,
+			value:   "2021-13-04",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var got time.Time
+			v := newTimeValue(&got, tc.layout)
+
+			err := v.Set(tc.value)
+			if tc.wantErr {
+				want := &ParseValueError{Type: "time", Err: ErrSyntax}
+				if !errors.Is(err, want) {
+					t.Fatalf("Set(%q): got error = %v, want error = %v", tc.value, err, want)
+				}
+
+				var pe *time.ParseError
+				if !errors.As(err, &pe) {
+					t.Errorf("Set(%q): got error = %v, want it to wrap *time.ParseError", tc.value, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Set(%q): failed to set value: %s", tc.value, err)
+			}
+
+			if !got.Equal(tc.want) {
+				t.Errorf("Set(%q): got = %v, want = %v", tc.value, got, tc.want)
+			}
+
+			// Formatted with the same layout.
+			if s := v.String(); s != tc.value {
+				t.Errorf("String(): got = %q, want = %q", s, tc.value)
+			}
+		})
+	}
+}
+
+func TestTime(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	since := Time(&register, "since", DateFlag)
+	until := Time(&register, "until", DateFlag)
+	at := TimeArg(&register, "at", DateTimeFlag)
+
+	args := []string{"--since", "2021-03-04", "2021-03-04 05:06:07"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if want := time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC); !since.Equal(want) {
+		t.Errorf("Parse(%v): since: got = %v, want = %v", args, *since, want)
+	}
+
+	if !until.IsZero() {
+		t.Errorf("Parse(%v): until: got = %v, want zero time", args, *until)
+	}
+
+	if want := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC); !at.Equal(want) {
+		t.Errorf("Parse(%v): at: got = %v, want = %v", args, *at, want)
+	}
+}
+
+func TestByteSizeValue_Set(t *testing.T) {
+	tt := []struct {
+		value      string
+		want       int64
+		wantString string
+	}{
+		{value: "0", want: 0, wantString: "0B"},
+		{value: "1500", want: 1500, wantString: "1500B"},
+		{value: "10B", want: 10, wantString: "10B"},
+		{value: "2KB", want: 2 << 10, wantString: "2KB"},
+		{value: "512MB", want: 512 << 20, wantString: "512MB"},
+		{value: "512mb", want: 512 << 20, wantString: "512MB"},
+		{value: "2 GB", want: 2 << 30, wantString: "2GB"},
+		{value: "1024GB", want: 1 << 40, wantString: "1TB"},
+		{value: "3tb", want: 3 << 40, wantString: "3TB"},
+		{value: "8PB", want: 8 << 50, wantString: "8PB"},
+		{value: "1536KB", want: 1536 << 10, wantString: "1536KB"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.value, func(t *testing.T) {
+			var got int64
+			v := newByteSizeValue(&got)
+
+			if err := v.Set(tc.value); err != nil {
+				t.Fatalf("Set(%q): failed to set value: %s", tc.value, err)
+			}
+
+			if got != tc.want {
+				t.Errorf("Set(%q): got = %v, want = %v", tc.value, got, tc.want)
+			}
+
+			if s := v.String(); s != tc.wantString {
+				t.Errorf("String(): got = %q, want = %q", s, tc.wantString)
+			}
+		})
+	}
+}
+
+func TestByteSizeValue_Set_broken_value(t *testing.T) {
+	tt := []struct {
+		value string
+		want  error
+	}{
+		{value: "", want: ErrSyntax},
+		{value: "MB", want: ErrSyntax},
+		{value: "10XB", want: ErrSyntax},
+		{value: "10K", want: ErrSyntax},
+		{value: "10  MB", want: ErrSyntax},
+		{value: "-10MB", want: ErrSyntax},
+		{value: "1.5GB", want: ErrSyntax},
+		{value: "9223372036854775808", want: ErrRange},
+		{value: "8192PB", want: ErrRange},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.value, func(t *testing.T) {
+			var got int64
+			err := newByteSizeValue(&got).Set(tc.value)
+
+			want := &ParseValueError{Type: "bytesize", Err: tc.want}
+			if !errors.Is(err, want) {
+				t.Fatalf("Set(%q): got error = %v, want error = %v", tc.value, err, want)
+			}
+		})
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	memory := ByteSize(&register, "memory")
+	disk := ByteSizeArg(&register, "disk")
+
+	args := []string{"--memory", "512MB", "2GB"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if *memory != 512<<20 {
+		t.Errorf("Parse(%v): memory: got = %v, want = %v", args, *memory, 512<<20)
+	}
+
+	if *disk != 2<<30 {
+		t.Errorf("Parse(%v): disk: got = %v, want = %v", args, *disk, 2<<30)
+	}
+}
+
+func TestFuncFlag(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		includes []string
+		file     string
+	)
+
+	errBroken := errors.New("broken include")
+
+	_ = FuncFlag(&register, "include", func(value string) error {
+		if value == "broken" {
+			return errBroken
+		}
+
+		includes = append(includes, value)
+		return nil
+	}, WithShort("I"))
+	_ = FuncArg(&register, "file", func(value string) error {
+		file = value
+		return nil
+	})
+
+	args := []string{"-I", "a", "--include=b", "main.c"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if want := []string{"a", "b"}; !reflect.DeepEqual(includes, want) {
+		t.Errorf("Parse(%v): includes: got = %v, want = %v", args, includes, want)
+	}
+
+	if file != "main.c" {
+		t.Errorf("Parse(%v): file: got = %q, want = %q", args, file, "main.c")
+	}
+
+	flag, _ := register.LongFlag("include")
+	if got := flag.Value.String(); got != "b" {
+		t.Errorf("String(): got = %q, want = %q", got, "b")
+	}
+
+	// Errors are returned as is.
+	args = []string{"--include", "broken", "main.c"}
+
+	err := parser.Parse(nil, &register, args)
+
+	var flagErr *FlagError
+	if !errors.As(err, &flagErr) || flagErr.Err != errBroken {
+		t.Fatalf("Parse(%v): got error = %v, want error = %v", args, err, errBroken)
+	}
+}
+
+func TestFuncFlag_nil(t *testing.T) {
+	var register DefaultRegister
+
+	want := &FlagError{Long: "include", Err: ErrNilTarget}
+	if err := FuncFlag(&register, "include", nil); !errors.Is(err, want) {
+		t.Errorf("FuncFlag(): got error = %v, want error = %v", err, want)
+	}
+}
+
+func TestSensitiveString(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+		buf      strings.Builder
+	)
+
+	password := SensitiveString(&register, "password", WithDefault("hunter2"), Usage("Password"))
+	token := SensitiveString(&register, "token")
+
+	flag, ok := register.LongFlag("password")
+	if !ok {
+		t.Fatalf("LongFlag(%q): flag not found", "password")
+	}
+
+	if !flag.Sensitive {
+		t.Errorf("SensitiveString(): flag must be sensitive")
+	}
+
+	if got := flag.Value.String(); got != "***" {
+		t.Errorf("String(): got = %q, want = %q", got, "***")
+	}
+
+	if err := parser.Parse(nil, &register, []string{"--token", "secret"}); err != nil {
+		t.Fatalf("Parse(): failed to parse args: %s", err)
+	}
+
+	if *password != "hunter2" || *token != "secret" {
+		t.Errorf("Parse(): got = (%q, %q), want = (%q, %q)", *password, *token, "hunter2", "secret")
+	}
+
+	for _, name := range []string{"password", "token"} {
+		flag, _ := register.LongFlag(name)
+		if got := flag.Value.String(); got != "***" {
+			t.Errorf("String(): %s: got = %q, want = %q", name, got, "***")
+		}
+	}
+
+	if err := parser.PrintDefaults(&buf); err != nil {
+		t.Fatalf("PrintDefaults(): failed to print defaults: %s", err)
+	}
+
+	want := "  --password <string>  Password\n" +
+		"  --token <string>\n"
+	if got := buf.String(); got != want {
+		t.Errorf("PrintDefaults(): got = %q, want = %q", got, want)
+	}
+}
+
+func TestFileString(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nice-test")
+	if err != nil {
+		t.Fatalf("TempDir(): failed to create dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token.txt")
+	if err := ioutil.WriteFile(path, []byte("  secret\n"), 0600); err != nil {
+		t.Fatalf("WriteFile(): failed to write file: %s", err)
+	}
+
+	tt := []struct {
+		name    string
+		options []FlagOptionApplyer
+		input   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "value",
+			input: "plain",
+			want:  "plain",
+		},
+		{
+			name:  "file",
+			input: "@" + path,
+			want:  "secret",
+		},
+		{
+			name:    "missing file",
+			input:   "@" + filepath.Join(dir, "missing.txt"),
+			wantErr: &ParseValueError{Type: "filestring", Err: ErrSyntax},
+		},
+		{
+			name:    "custom prefix",
+			options: []FlagOptionApplyer{WithFilePrefix("file:")},
+			input:   "file:" + path,
+			want:    "secret",
+		},
+		{
+			name:    "custom prefix value",
+			options: []FlagOptionApplyer{WithFilePrefix("file:")},
+			input:   "@" + path,
+			want:    "@" + path,
+		},
+		{
+			name:    "disabled",
+			options: []FlagOptionApplyer{WithFilePrefix("")},
+			input:   "@" + path,
+			want:    "@" + path,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			token := FileString(&register, "token", tc.options...)
+
+			err := parser.Parse(nil, &register, []string{"--token", tc.input})
+			if tc.wantErr != nil {
+				var fe *FlagError
+				if !errors.As(err, &fe) || !errors.Is(fe.Err, tc.wantErr) {
+					t.Fatalf("Parse(): got error = %v, want = %v", err, tc.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse(): failed to parse args: %s", err)
+			}
+
+			if *token != tc.want {
+				t.Errorf("Parse(): got = %q, want = %q", *token, tc.want)
+			}
+		})
+	}
+}
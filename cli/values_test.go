@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -89,3 +90,201 @@ func TestValues_String(t *testing.T) {
 		})
 	}
 }
+
+func TestStringSlice(t *testing.T) {
+	tt := []struct {
+		name       string
+		args       []string
+		want       []string
+		wantString string
+	}{
+		{
+			name:       "zero",
+			args:       nil,
+			want:       nil,
+			wantString: "[]",
+		},
+		{
+			name:       "one",
+			args:       []string{"--tag", "foo"},
+			want:       []string{"foo"},
+			wantString: `["foo"]`,
+		},
+		{
+			name:       "multiple",
+			args:       []string{"--tag", "foo", "--tag=bar,baz", "--tag", `"qux"`},
+			want:       []string{"foo", "bar,baz", `"qux"`},
+			wantString: `["foo","bar,baz","\"qux\""]`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			tags := StringSlice(&register, "tag")
+
+			if err := parser.Parse(nil, &register, tc.args); err != nil {
+				t.Fatalf("Parse(%v): failed to parse args: %s", tc.args, err)
+			}
+
+			if !reflect.DeepEqual(*tags, tc.want) {
+				t.Errorf("Parse(%v): got = %#v, want = %#v", tc.args, *tags, tc.want)
+			}
+
+			flag, _ := register.LongFlag("tag")
+			if got := flag.Value.String(); got != tc.wantString {
+				t.Errorf("String(): got = %q, want = %q", got, tc.wantString)
+			}
+		})
+	}
+}
+
+func TestStringSliceArg(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	tags := StringSliceArg(&register, "tags")
+
+	args := []string{"a,b,c"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	want := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(*tags, want) {
+		t.Errorf("Parse(%v): got = %#v, want = %#v", args, *tags, want)
+	}
+}
+
+func TestIntSlice(t *testing.T) {
+	var (
+		register DefaultRegister
+		parser   DefaultParser
+	)
+
+	ports := IntSlice(&register, "port")
+	offsets := Int64Slice(&register, "offset")
+
+	args := []string{"--port", "8080", "--port=9090", "--offset", "-9223372036854775808"}
+
+	if err := parser.Parse(nil, &register, args); err != nil {
+		t.Fatalf("Parse(%v): failed to parse args: %s", args, err)
+	}
+
+	if want := []int{8080, 9090}; !reflect.DeepEqual(*ports, want) {
+		t.Errorf("Parse(%v): got ports = %#v, want = %#v", args, *ports, want)
+	}
+
+	if want := []int64{-9223372036854775808}; !reflect.DeepEqual(*offsets, want) {
+		t.Errorf("Parse(%v): got offsets = %#v, want = %#v", args, *offsets, want)
+	}
+
+	if got, want := newIntSliceValue(ports).String(), "[8080,9090]"; got != want {
+		t.Errorf("String(): got = %q, want = %q", got, want)
+	}
+
+	if got, want := newInt64SliceValue(new([]int64)).String(), "[]"; got != want {
+		t.Errorf("String(): got = %q, want = %q", got, want)
+	}
+}
+
+func TestIntSlice_broken_value(t *testing.T) {
+	tt := []struct {
+		name    string
+		setup   func(r Register) interface{}
+		args    []string
+		want    interface{}
+		wantErr error
+	}{
+		{
+			name:    "int",
+			setup:   func(r Register) interface{} { return IntSlice(r, "n") },
+			args:    []string{"-n", "1", "-n", "two", "-n", "3"},
+			want:    &[]int{1},
+			wantErr: &FlagError{Short: "n", Err: &ParseValueError{Type: "int", Err: ErrSyntax}},
+		},
+		{
+			name:    "int64",
+			setup:   func(r Register) interface{} { return Int64Slice(r, "n") },
+			args:    []string{"-n", "1", "-n", "9223372036854775808"},
+			want:    &[]int64{1},
+			wantErr: &FlagError{Short: "n", Err: &ParseValueError{Type: "int64", Err: ErrRange}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			got := tc.setup(&register)
+
+			err := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Parse(%v): got = %v, want = %v", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStringMap(t *testing.T) {
+	tt := []struct {
+		name    string
+		args    []string
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name: "multiple",
+			args: []string{"--label", "app=web", "--label=env=prod", "--label", "app=db"},
+			want: map[string]string{"app": "db", "env": "prod"},
+		},
+		{
+			name: "empty value",
+			args: []string{"--label", "app="},
+			want: map[string]string{"app": ""},
+		},
+		{
+			name: "missing equals",
+			args: []string{"--label", "app=web", "--label", "env"},
+			want: map[string]string{"app": "web"},
+			wantErr: &FlagError{
+				Long: "label",
+				Err:  &ParseValueError{Type: "stringmap", Err: ErrSyntax},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				register DefaultRegister
+				parser   DefaultParser
+			)
+
+			labels := StringMap(&register, "label")
+
+			err := parser.Parse(nil, &register, tc.args)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Parse(%v): got error = %v, want error = %v", tc.args, err, tc.wantErr)
+			}
+
+			if !reflect.DeepEqual(*labels, tc.want) {
+				t.Errorf("Parse(%v): got = %#v, want = %#v", tc.args, *labels, tc.want)
+			}
+		})
+	}
+}